@@ -19,8 +19,13 @@ type MouseButton struct {
 }
 
 type MouseMotion struct {
-	X int
-	Y int
+	X      int
+	Y      int
+	Button ebiten.MouseButton
+	Drag   bool
+	Shift  bool
+	Alt    bool
+	Ctrl   bool
 }
 
 type MouseWheel struct {
@@ -48,3 +53,11 @@ type InputAdapter interface {
 	HandleKeyPress()
 	HandleWindowSize(size WindowSize)
 }
+
+// KeyFilterAdapter is an InputAdapter that can leave out single keys. The window uses some keys
+// itself (e.g. Shift+PageUp to scroll back), so HandleKeyPressExcept is called instead of
+// HandleKeyPress and the keys for which used returns true must not be passed on.
+type KeyFilterAdapter interface {
+	InputAdapter
+	HandleKeyPressExcept(used func(key ebiten.Key) bool)
+}
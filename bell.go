@@ -0,0 +1,54 @@
+package crt
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image/color"
+	"time"
+)
+
+const (
+	// bellInterval is the minimum time between two bells. Bells in between are dropped.
+	bellInterval = 100 * time.Millisecond
+
+	// visualBellDuration is how long the screen flashes for the visual bell.
+	visualBellDuration = 100 * time.Millisecond
+)
+
+// WithVisualBell enables or disables the visual bell, which briefly flashes the screen if the bell rings.
+func WithVisualBell(enabled bool) WindowOption {
+	return func(window *Window) {
+		window.SetVisualBell(enabled)
+	}
+}
+
+// SetVisualBell enables or disables the visual bell, which briefly flashes the screen if the bell rings.
+func (g *Window) SetVisualBell(enabled bool) {
+	g.visualBell = enabled
+}
+
+// ring handles the bell. It is rate-limited, so a flood of BEL characters only rings once per bellInterval.
+func (g *Window) ring() {
+	now := time.Now()
+	if now.Sub(g.lastBell) < bellInterval {
+		return
+	}
+	g.lastBell = now
+
+	if g.visualBell {
+		g.visualBellUntil = now.Add(visualBellDuration)
+	}
+	g.onBell()
+}
+
+// drawVisualBell flashes the screen while the visual bell is active.
+func (g *Window) drawVisualBell(screen *ebiten.Image) {
+	if !time.Now().Before(g.visualBellUntil) {
+		return
+	}
+
+	r, gr, b, _ := g.defaultFg.RGBA()
+	flash := color.NRGBA{R: uint8(r >> 8), G: uint8(gr >> 8), B: uint8(b >> 8), A: 0x60}
+	bounds := screen.Bounds()
+	vector.DrawFilledRect(screen, float32(bounds.Min.X), float32(bounds.Min.Y), float32(bounds.Dx()), float32(bounds.Dy()), flash, false)
+}
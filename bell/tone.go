@@ -0,0 +1,74 @@
+package bell
+
+import (
+	"encoding/binary"
+	"github.com/hajimehoshi/ebiten/v2/audio"
+	"math"
+	"time"
+)
+
+// sampleRate is used if no audio context exists yet.
+const sampleRate = 44100
+
+// fadeDuration is the length of the fade in and out of the tone, which prevents clicks.
+const fadeDuration = 5 * time.Millisecond
+
+// Tone is an audible bell that plays a generated sine tone, so no audio files are needed.
+//
+// Example: window.SetOnBell(bell.NewTone(880, 100*time.Millisecond, 0.3).Ring)
+type Tone struct {
+	context *audio.Context
+	samples []byte
+	volume  float64
+	player  *audio.Player
+}
+
+// NewTone creates an audible bell with the given frequency in Hz, duration and volume between 0 and 1.
+// The current audio context of ebiten is used or a new one is created.
+func NewTone(frequency float64, duration time.Duration, volume float64) *Tone {
+	context := audio.CurrentContext()
+	if context == nil {
+		context = audio.NewContext(sampleRate)
+	}
+
+	return &Tone{
+		context: context,
+		samples: generateTone(context.SampleRate(), frequency, duration),
+		volume:  volume,
+	}
+}
+
+// Ring plays the tone from the start. It can be passed to Window.SetOnBell.
+func (t *Tone) Ring() {
+	if t.player == nil {
+		t.player = t.context.NewPlayerFromBytes(t.samples)
+		t.player.SetVolume(t.volume)
+	}
+
+	if err := t.player.Rewind(); err != nil {
+		return
+	}
+	t.player.Play()
+}
+
+// generateTone creates a sine tone as 16 bit little endian stereo samples, which is the format of ebiten's audio package.
+func generateTone(sampleRate int, frequency float64, duration time.Duration) []byte {
+	count := int(duration.Seconds() * float64(sampleRate))
+	fade := int(fadeDuration.Seconds() * float64(sampleRate))
+
+	samples := make([]byte, count*4)
+	for i := 0; i < count; i++ {
+		amplitude := 1.0
+		if i < fade {
+			amplitude = float64(i) / float64(fade)
+		} else if count-i < fade {
+			amplitude = float64(count-i) / float64(fade)
+		}
+
+		value := int16(amplitude * math.MaxInt16 * math.Sin(2*math.Pi*frequency*float64(i)/float64(sampleRate)))
+		binary.LittleEndian.PutUint16(samples[i*4:], uint16(value))
+		binary.LittleEndian.PutUint16(samples[i*4+2:], uint16(value))
+	}
+
+	return samples
+}
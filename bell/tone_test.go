@@ -0,0 +1,27 @@
+package bell
+
+import (
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestGenerateTone(t *testing.T) {
+	samples := generateTone(44100, 441, 100*time.Millisecond)
+	assert.Len(t, samples, 4410*4)
+
+	// Both channels are the same and the tone fades in and out.
+	maxValue := int16(0)
+	for i := 0; i < len(samples); i += 4 {
+		left := int16(binary.LittleEndian.Uint16(samples[i:]))
+		right := int16(binary.LittleEndian.Uint16(samples[i+2:]))
+		assert.Equal(t, left, right)
+		if left > maxValue {
+			maxValue = left
+		}
+	}
+	assert.Greater(t, maxValue, int16(30000))
+	assert.Equal(t, int16(0), int16(binary.LittleEndian.Uint16(samples)))
+	assert.Less(t, int16(binary.LittleEndian.Uint16(samples[len(samples)-4:])), int16(200))
+}
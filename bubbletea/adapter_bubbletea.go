@@ -5,14 +5,8 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
-	"unicode"
 )
 
-type teaKey struct {
-	key  tea.KeyType
-	rune []rune
-}
-
 func repeatingKeyPressed(key ebiten.Key) bool {
 	const (
 		delay    = 30
@@ -28,32 +22,53 @@ func repeatingKeyPressed(key ebiten.Key) bool {
 	return false
 }
 
-var ebitenToTeaKeys = map[ebiten.Key]teaKey{
-	ebiten.KeyEnter:      {tea.KeyEnter, []rune{'\n'}},
-	ebiten.KeyTab:        {tea.KeyTab, []rune{}},
-	ebiten.KeyBackspace:  {tea.KeyBackspace, []rune{}},
-	ebiten.KeyDelete:     {tea.KeyDelete, []rune{}},
-	ebiten.KeyHome:       {tea.KeyHome, []rune{}},
-	ebiten.KeyEnd:        {tea.KeyEnd, []rune{}},
-	ebiten.KeyPageUp:     {tea.KeyPgUp, []rune{}},
-	ebiten.KeyArrowUp:    {tea.KeyUp, []rune{}},
-	ebiten.KeyArrowDown:  {tea.KeyDown, []rune{}},
-	ebiten.KeyArrowLeft:  {tea.KeyLeft, []rune{}},
-	ebiten.KeyArrowRight: {tea.KeyRight, []rune{}},
-	ebiten.KeyEscape:     {tea.KeyEscape, []rune{}},
-	ebiten.KeyF1:         {tea.KeyF1, []rune{}},
-	ebiten.KeyF2:         {tea.KeyF2, []rune{}},
-	ebiten.KeyF3:         {tea.KeyF3, []rune{}},
-	ebiten.KeyF4:         {tea.KeyF4, []rune{}},
-	ebiten.KeyF5:         {tea.KeyF5, []rune{}},
-	ebiten.KeyF6:         {tea.KeyF6, []rune{}},
-	ebiten.KeyF7:         {tea.KeyF7, []rune{}},
-	ebiten.KeyF8:         {tea.KeyF8, []rune{}},
-	ebiten.KeyF9:         {tea.KeyF9, []rune{}},
-	ebiten.KeyF10:        {tea.KeyF10, []rune{}},
-	ebiten.KeyF11:        {tea.KeyF11, []rune{}},
-	ebiten.KeyF12:        {tea.KeyF12, []rune{}},
-	ebiten.KeyShift:      {tea.KeyShiftLeft, []rune{}},
+// ebitenToTeaKeys maps the keys that don't produce text. Keys that are missing are sent
+// as runes from the input characters. The digits and the decimal point of the keypad are only
+// sent as keys if NumLock is off.
+var ebitenToTeaKeys = map[ebiten.Key]tea.KeyType{
+	ebiten.KeyEnter:         tea.KeyEnter,
+	ebiten.KeyNumpadEnter:   tea.KeyEnter,
+	ebiten.KeyBackspace:     tea.KeyBackspace,
+	ebiten.KeyDelete:        tea.KeyDelete,
+	ebiten.KeyNumpadDecimal: tea.KeyDelete,
+	ebiten.KeyInsert:        tea.KeyInsert,
+	ebiten.KeyNumpad0:       tea.KeyInsert,
+	ebiten.KeyEscape:        tea.KeyEscape,
+	ebiten.KeyF9:            tea.KeyF9,
+	ebiten.KeyF10:           tea.KeyF10,
+	ebiten.KeyF11:           tea.KeyF11,
+	ebiten.KeyF12:           tea.KeyF12,
+}
+
+// ebitenToModifiedKeys maps the keys that bubbletea distinguishes by their modifiers. The
+// entries are the key types without a modifier, with Shift, with Ctrl and with Ctrl+Shift.
+// Like in xterm Shift+F1 to Shift+F8 are F13 to F20.
+var ebitenToModifiedKeys = map[ebiten.Key][4]tea.KeyType{
+	ebiten.KeyArrowUp:    {tea.KeyUp, tea.KeyShiftUp, tea.KeyCtrlUp, tea.KeyCtrlShiftUp},
+	ebiten.KeyNumpad8:    {tea.KeyUp, tea.KeyShiftUp, tea.KeyCtrlUp, tea.KeyCtrlShiftUp},
+	ebiten.KeyArrowDown:  {tea.KeyDown, tea.KeyShiftDown, tea.KeyCtrlDown, tea.KeyCtrlShiftDown},
+	ebiten.KeyNumpad2:    {tea.KeyDown, tea.KeyShiftDown, tea.KeyCtrlDown, tea.KeyCtrlShiftDown},
+	ebiten.KeyArrowLeft:  {tea.KeyLeft, tea.KeyShiftLeft, tea.KeyCtrlLeft, tea.KeyCtrlShiftLeft},
+	ebiten.KeyNumpad4:    {tea.KeyLeft, tea.KeyShiftLeft, tea.KeyCtrlLeft, tea.KeyCtrlShiftLeft},
+	ebiten.KeyArrowRight: {tea.KeyRight, tea.KeyShiftRight, tea.KeyCtrlRight, tea.KeyCtrlShiftRight},
+	ebiten.KeyNumpad6:    {tea.KeyRight, tea.KeyShiftRight, tea.KeyCtrlRight, tea.KeyCtrlShiftRight},
+	ebiten.KeyHome:       {tea.KeyHome, tea.KeyShiftHome, tea.KeyCtrlHome, tea.KeyCtrlShiftHome},
+	ebiten.KeyNumpad7:    {tea.KeyHome, tea.KeyShiftHome, tea.KeyCtrlHome, tea.KeyCtrlShiftHome},
+	ebiten.KeyEnd:        {tea.KeyEnd, tea.KeyShiftEnd, tea.KeyCtrlEnd, tea.KeyCtrlShiftEnd},
+	ebiten.KeyNumpad1:    {tea.KeyEnd, tea.KeyShiftEnd, tea.KeyCtrlEnd, tea.KeyCtrlShiftEnd},
+	ebiten.KeyPageUp:     {tea.KeyPgUp, tea.KeyPgUp, tea.KeyCtrlPgUp, tea.KeyCtrlPgUp},
+	ebiten.KeyNumpad9:    {tea.KeyPgUp, tea.KeyPgUp, tea.KeyCtrlPgUp, tea.KeyCtrlPgUp},
+	ebiten.KeyPageDown:   {tea.KeyPgDown, tea.KeyPgDown, tea.KeyCtrlPgDown, tea.KeyCtrlPgDown},
+	ebiten.KeyNumpad3:    {tea.KeyPgDown, tea.KeyPgDown, tea.KeyCtrlPgDown, tea.KeyCtrlPgDown},
+	ebiten.KeyTab:        {tea.KeyTab, tea.KeyShiftTab, tea.KeyTab, tea.KeyShiftTab},
+	ebiten.KeyF1:         {tea.KeyF1, tea.KeyF13, tea.KeyF1, tea.KeyF13},
+	ebiten.KeyF2:         {tea.KeyF2, tea.KeyF14, tea.KeyF2, tea.KeyF14},
+	ebiten.KeyF3:         {tea.KeyF3, tea.KeyF15, tea.KeyF3, tea.KeyF15},
+	ebiten.KeyF4:         {tea.KeyF4, tea.KeyF16, tea.KeyF4, tea.KeyF16},
+	ebiten.KeyF5:         {tea.KeyF5, tea.KeyF17, tea.KeyF5, tea.KeyF17},
+	ebiten.KeyF6:         {tea.KeyF6, tea.KeyF18, tea.KeyF6, tea.KeyF18},
+	ebiten.KeyF7:         {tea.KeyF7, tea.KeyF19, tea.KeyF7, tea.KeyF19},
+	ebiten.KeyF8:         {tea.KeyF8, tea.KeyF20, tea.KeyF8, tea.KeyF20},
 }
 
 var ebitenToCtrlKeys = map[ebiten.Key]tea.KeyType{
@@ -87,22 +102,26 @@ var ebitenToCtrlKeys = map[ebiten.Key]tea.KeyType{
 	ebiten.KeyBackslash:    tea.KeyCtrlBackslash,
 	ebiten.KeyRightBracket: tea.KeyCtrlCloseBracket,
 	ebiten.KeyApostrophe:   tea.KeyCtrlCaret,
+	ebiten.KeyDigit6:       tea.KeyCtrlCaret,
+	ebiten.KeySpace:        tea.KeyCtrlAt,
+	ebiten.KeyDigit2:       tea.KeyCtrlAt,
+	ebiten.KeySlash:        tea.KeyCtrlUnderscore,
 }
 
 var ebitenToTeaMouse = map[ebiten.MouseButton]tea.MouseEventType{
 	ebiten.MouseButtonLeft:   tea.MouseLeft,
 	ebiten.MouseButtonMiddle: tea.MouseMiddle,
 	ebiten.MouseButtonRight:  tea.MouseRight,
+	ebiten.MouseButton3:      tea.MouseBackward,
+	ebiten.MouseButton4:      tea.MouseForward,
 }
 
 var ebitenToTeaMouseNew = map[ebiten.MouseButton]tea.MouseButton{
 	ebiten.MouseButtonLeft:   tea.MouseButtonLeft,
 	ebiten.MouseButtonMiddle: tea.MouseButtonMiddle,
 	ebiten.MouseButtonRight:  tea.MouseButtonRight,
-
-	// TODO: is this right?
-	ebiten.MouseButton3: tea.MouseButtonBackward,
-	ebiten.MouseButton4: tea.MouseButtonForward,
+	ebiten.MouseButton3:      tea.MouseButtonBackward,
+	ebiten.MouseButton4:      tea.MouseButtonForward,
 }
 
 // Options are used to configure the adapter.
@@ -119,11 +138,12 @@ func WithFilterMousePressed(filter bool) Options {
 type Adapter struct {
 	prog               *tea.Program
 	filterMousePressed bool
+	numLock            bool
 }
 
 // NewAdapter creates a new bubbletea adapter.
 func NewAdapter(prog *tea.Program, options ...Options) *Adapter {
-	b := &Adapter{prog: prog, filterMousePressed: true}
+	b := &Adapter{prog: prog, filterMousePressed: true, numLock: true}
 
 	for i := range options {
 		options[i](b)
@@ -133,14 +153,7 @@ func NewAdapter(prog *tea.Program, options ...Options) *Adapter {
 }
 
 func (b *Adapter) HandleMouseMotion(motion crt.MouseMotion) {
-	b.prog.Send(tea.MouseMsg{
-		X:      motion.X,
-		Y:      motion.Y,
-		Alt:    false,
-		Ctrl:   false,
-		Type:   tea.MouseMotion,
-		Action: tea.MouseActionMotion,
-	})
+	b.prog.Send(mouseMotionMsg(motion))
 }
 
 func (b *Adapter) HandleMouseButton(button crt.MouseButton) {
@@ -149,108 +162,169 @@ func (b *Adapter) HandleMouseButton(button crt.MouseButton) {
 		return
 	}
 
+	b.prog.Send(mouseButtonMsg(button))
+}
+
+func (b *Adapter) HandleMouseWheel(wheel crt.MouseWheel) {
+	for _, msg := range mouseWheelMsgs(wheel) {
+		b.prog.Send(msg)
+	}
+}
+
+// mouseMotionMsg converts a mouse motion to the message bubbletea creates for a motion report.
+// Motion with a button held is reported with that button.
+func mouseMotionMsg(motion crt.MouseMotion) tea.MouseMsg {
+	msg := tea.MouseMsg{
+		X:      motion.X,
+		Y:      motion.Y,
+		Shift:  motion.Shift,
+		Alt:    motion.Alt,
+		Ctrl:   motion.Ctrl,
+		Type:   tea.MouseMotion,
+		Action: tea.MouseActionMotion,
+	}
+
+	if motion.Drag {
+		msg.Button = ebitenToTeaMouseNew[motion.Button]
+		if t, ok := ebitenToTeaMouse[motion.Button]; ok {
+			msg.Type = t
+		}
+	}
+
+	return msg
+}
+
+// mouseButtonMsg converts the press or release of a mouse button to the message bubbletea creates for it.
+func mouseButtonMsg(button crt.MouseButton) tea.MouseMsg {
 	msg := tea.MouseMsg{
 		X:      button.X,
 		Y:      button.Y,
-		Alt:    ebiten.IsKeyPressed(ebiten.KeyAlt),
-		Ctrl:   ebiten.IsKeyPressed(ebiten.KeyControl),
+		Shift:  button.Shift,
+		Alt:    button.Alt,
+		Ctrl:   button.Ctrl,
 		Type:   ebitenToTeaMouse[button.Button],
 		Button: ebitenToTeaMouseNew[button.Button],
 	}
 
 	if button.JustReleased {
 		msg.Action = tea.MouseActionRelease
+		msg.Type = tea.MouseRelease
 	} else if button.JustPressed {
 		msg.Action = tea.MouseActionPress
 	}
 
-	b.prog.Send(msg)
+	return msg
 }
 
-func (b *Adapter) HandleMouseWheel(wheel crt.MouseWheel) {
-	if wheel.DY > 0 {
-		b.prog.Send(tea.MouseMsg{
-			X:    wheel.X,
-			Y:    wheel.Y,
-			Alt:  ebiten.IsKeyPressed(ebiten.KeyAlt),
-			Ctrl: ebiten.IsKeyPressed(ebiten.KeyControl),
-			Type: tea.MouseWheelUp,
+// mouseWheelMsgs converts a wheel motion to the messages of the vertical and horizontal wheel.
+// Positive deltas scroll up and to the left.
+func mouseWheelMsgs(wheel crt.MouseWheel) []tea.MouseMsg {
+	var msgs []tea.MouseMsg
+	add := func(t tea.MouseEventType, button tea.MouseButton) {
+		msgs = append(msgs, tea.MouseMsg{
+			X:      wheel.X,
+			Y:      wheel.Y,
+			Shift:  wheel.Shift,
+			Alt:    wheel.Alt,
+			Ctrl:   wheel.Ctrl,
+			Type:   t,
+			Button: button,
+			Action: tea.MouseActionPress,
 		})
+	}
+
+	if wheel.DY > 0 {
+		add(tea.MouseWheelUp, tea.MouseButtonWheelUp)
 	} else if wheel.DY < 0 {
-		b.prog.Send(tea.MouseMsg{
-			X:    wheel.X,
-			Y:    wheel.Y,
-			Alt:  ebiten.IsKeyPressed(ebiten.KeyAlt),
-			Ctrl: ebiten.IsKeyPressed(ebiten.KeyControl),
-			Type: tea.MouseWheelDown,
-		})
+		add(tea.MouseWheelDown, tea.MouseButtonWheelDown)
 	}
+
+	if wheel.DX > 0 {
+		add(tea.MouseWheelLeft, tea.MouseButtonWheelLeft)
+	} else if wheel.DX < 0 {
+		add(tea.MouseWheelRight, tea.MouseButtonWheelRight)
+	}
+
+	return msgs
 }
 
 func (b *Adapter) HandleKeyPress() {
-	newInputs := ebiten.AppendInputChars([]rune{})
-	for _, v := range newInputs {
-		switch v {
-		case ' ':
-			b.prog.Send(tea.KeyMsg{
-				Type:  tea.KeySpace,
-				Runes: []rune{v},
-				Alt:   ebiten.IsKeyPressed(ebiten.KeyAlt),
-			})
-		default:
-			b.prog.Send(tea.KeyMsg{
-				Type:  tea.KeyRunes,
-				Runes: []rune{v},
-				Alt:   ebiten.IsKeyPressed(ebiten.KeyAlt),
-			})
-		}
-	}
+	b.HandleKeyPressExcept(func(key ebiten.Key) bool {
+		return false
+	})
+}
 
-	var keys []ebiten.Key
-	keys = inpututil.AppendJustPressedKeys(keys)
-	repeatedBackspace := repeatingKeyPressed(ebiten.KeyBackspace)
+// HandleKeyPressExcept sends the typed characters and the pressed keys to the program, except the keys that the window used.
+func (b *Adapter) HandleKeyPressExcept(used func(key ebiten.Key) bool) {
+	shift := ebiten.IsKeyPressed(ebiten.KeyShift)
+	alt := ebiten.IsKeyPressed(ebiten.KeyAlt)
+	ctrl := ebiten.IsKeyPressed(ebiten.KeyControl)
 
-	if repeatedBackspace {
-		b.prog.Send(tea.KeyMsg{
-			Type:  tea.KeyBackspace,
-			Runes: []rune{},
-			Alt:   false,
-		})
+	chars := ebiten.AppendInputChars(nil)
+	for _, r := range chars {
+		b.prog.Send(runeMsg(r, alt))
 	}
 
-	for _, k := range keys {
-		if ebiten.IsKeyPressed(ebiten.KeyControl) {
-			if tk, ok := ebitenToCtrlKeys[k]; ok {
-				b.prog.Send(tea.KeyMsg{
-					Type:  tk,
-					Runes: []rune{},
-					Alt:   false,
-				})
+	for _, k := range inpututil.AppendPressedKeys(nil) {
+		if used(k) || !repeatingKeyPressed(k) {
+			continue
+		}
+
+		// ebiten doesn't report NumLock. A keypad key that types a character when it is pressed
+		// has NumLock on, so the character was already sent.
+		if isKeypadKey(k) {
+			if inpututil.IsKeyJustPressed(k) {
+				b.numLock = len(chars) > 0
+			}
+			if b.numLock {
 				continue
 			}
 		}
 
-		if repeatedBackspace && k == ebiten.KeyBackspace {
-			continue
+		if msg, ok := keyMsg(k, shift, alt, ctrl); ok {
+			b.prog.Send(msg)
 		}
+	}
+}
 
-		if val, ok := ebitenToTeaKeys[k]; ok {
-			runes := make([]rune, len(val.rune))
-			copy(runes, val.rune)
+// isKeypadKey returns true for the keys of the keypad that move the cursor if NumLock is off.
+func isKeypadKey(key ebiten.Key) bool {
+	return key >= ebiten.KeyNumpad0 && key <= ebiten.KeyNumpad9 || key == ebiten.KeyNumpadDecimal
+}
 
-			if ebiten.IsKeyPressed(ebiten.KeyShift) {
-				for i := range runes {
-					runes[i] = unicode.ToUpper(runes[i])
-				}
-			}
+// runeMsg returns the message bubbletea creates for a typed character.
+func runeMsg(r rune, alt bool) tea.KeyMsg {
+	if r == ' ' {
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}, Alt: alt}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: alt}
+}
 
-			b.prog.Send(tea.KeyMsg{
-				Type:  val.key,
-				Runes: runes,
-				Alt:   ebiten.IsKeyPressed(ebiten.KeyAlt),
-			})
+// keyMsg returns the message bubbletea creates for a key that doesn't produce text. Alt is passed
+// as flag, like bubbletea does for the escape prefix. Modifier keys on their own are not sent.
+func keyMsg(key ebiten.Key, shift, alt, ctrl bool) (tea.KeyMsg, bool) {
+	if ctrl {
+		if t, ok := ebitenToCtrlKeys[key]; ok {
+			return tea.KeyMsg{Type: t, Alt: alt}, true
 		}
 	}
+
+	if types, ok := ebitenToModifiedKeys[key]; ok {
+		i := 0
+		if shift {
+			i |= 1
+		}
+		if ctrl {
+			i |= 2
+		}
+		return tea.KeyMsg{Type: types[i], Alt: alt}, true
+	}
+
+	if t, ok := ebitenToTeaKeys[key]; ok {
+		return tea.KeyMsg{Type: t, Alt: alt}, true
+	}
+
+	return tea.KeyMsg{}, false
 }
 
 func (b *Adapter) HandleWindowSize(size crt.WindowSize) {
@@ -0,0 +1,141 @@
+package bubbletea
+
+import (
+	"github.com/BigJk/crt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMouseButtonMsg(t *testing.T) {
+	tests := []struct {
+		button crt.MouseButton
+		want   tea.MouseMsg
+	}{
+		{
+			crt.MouseButton{X: 1, Y: 2, Button: ebiten.MouseButtonRight, JustPressed: true},
+			tea.MouseMsg{X: 1, Y: 2, Type: tea.MouseRight, Button: tea.MouseButtonRight, Action: tea.MouseActionPress},
+		},
+		{
+			crt.MouseButton{X: 1, Y: 2, Button: ebiten.MouseButtonMiddle, JustReleased: true, Shift: true},
+			tea.MouseMsg{X: 1, Y: 2, Shift: true, Type: tea.MouseRelease, Button: tea.MouseButtonMiddle, Action: tea.MouseActionRelease},
+		},
+		{
+			crt.MouseButton{Button: ebiten.MouseButton3, JustPressed: true, Ctrl: true},
+			tea.MouseMsg{Ctrl: true, Type: tea.MouseBackward, Button: tea.MouseButtonBackward, Action: tea.MouseActionPress},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, mouseButtonMsg(test.button))
+	}
+}
+
+func TestMouseMotionMsg(t *testing.T) {
+	assert.Equal(t,
+		tea.MouseMsg{X: 3, Y: 4, Type: tea.MouseMotion, Action: tea.MouseActionMotion},
+		mouseMotionMsg(crt.MouseMotion{X: 3, Y: 4}),
+	)
+	assert.Equal(t,
+		tea.MouseMsg{X: 3, Y: 4, Alt: true, Type: tea.MouseLeft, Button: tea.MouseButtonLeft, Action: tea.MouseActionMotion},
+		mouseMotionMsg(crt.MouseMotion{X: 3, Y: 4, Button: ebiten.MouseButtonLeft, Drag: true, Alt: true}),
+	)
+}
+
+func TestMouseWheelMsgs(t *testing.T) {
+	wheel := func(dx, dy float64) []tea.MouseEventType {
+		var types []tea.MouseEventType
+		for _, msg := range mouseWheelMsgs(crt.MouseWheel{DX: dx, DY: dy}) {
+			assert.True(t, tea.MouseEvent(msg).IsWheel())
+			types = append(types, msg.Type)
+		}
+		return types
+	}
+
+	assert.Equal(t, []tea.MouseEventType{tea.MouseWheelUp}, wheel(0, 1))
+	assert.Equal(t, []tea.MouseEventType{tea.MouseWheelDown}, wheel(0, -0.5))
+	assert.Equal(t, []tea.MouseEventType{tea.MouseWheelLeft}, wheel(1, 0))
+	assert.Equal(t, []tea.MouseEventType{tea.MouseWheelDown, tea.MouseWheelRight}, wheel(-1, -1))
+	assert.Nil(t, wheel(0, 0))
+}
+
+func TestKeyMsg(t *testing.T) {
+	const (
+		none = iota
+		shift
+		alt
+		ctrl
+		ctrlShift
+		ctrlAlt
+	)
+
+	tests := []struct {
+		key  ebiten.Key
+		mods int
+		want tea.KeyMsg
+	}{
+		{ebiten.KeyEnter, none, tea.KeyMsg{Type: tea.KeyEnter}},
+		{ebiten.KeyNumpadEnter, none, tea.KeyMsg{Type: tea.KeyEnter}},
+		{ebiten.KeyBackspace, alt, tea.KeyMsg{Type: tea.KeyBackspace, Alt: true}},
+		{ebiten.KeyEscape, none, tea.KeyMsg{Type: tea.KeyEscape}},
+		{ebiten.KeyInsert, none, tea.KeyMsg{Type: tea.KeyInsert}},
+		{ebiten.KeyDelete, none, tea.KeyMsg{Type: tea.KeyDelete}},
+		{ebiten.KeyPageUp, none, tea.KeyMsg{Type: tea.KeyPgUp}},
+		{ebiten.KeyPageDown, none, tea.KeyMsg{Type: tea.KeyPgDown}},
+		{ebiten.KeyPageDown, ctrl, tea.KeyMsg{Type: tea.KeyCtrlPgDown}},
+		{ebiten.KeyTab, none, tea.KeyMsg{Type: tea.KeyTab}},
+		{ebiten.KeyTab, shift, tea.KeyMsg{Type: tea.KeyShiftTab}},
+		{ebiten.KeyArrowUp, none, tea.KeyMsg{Type: tea.KeyUp}},
+		{ebiten.KeyArrowUp, shift, tea.KeyMsg{Type: tea.KeyShiftUp}},
+		{ebiten.KeyArrowUp, alt, tea.KeyMsg{Type: tea.KeyUp, Alt: true}},
+		{ebiten.KeyArrowLeft, ctrl, tea.KeyMsg{Type: tea.KeyCtrlLeft}},
+		{ebiten.KeyArrowRight, ctrlShift, tea.KeyMsg{Type: tea.KeyCtrlShiftRight}},
+		{ebiten.KeyArrowDown, ctrlAlt, tea.KeyMsg{Type: tea.KeyCtrlDown, Alt: true}},
+		{ebiten.KeyHome, ctrl, tea.KeyMsg{Type: tea.KeyCtrlHome}},
+		{ebiten.KeyEnd, ctrl, tea.KeyMsg{Type: tea.KeyCtrlEnd}},
+		{ebiten.KeyEnd, shift, tea.KeyMsg{Type: tea.KeyShiftEnd}},
+		{ebiten.KeyHome, ctrlShift, tea.KeyMsg{Type: tea.KeyCtrlShiftHome}},
+		{ebiten.KeyNumpad7, none, tea.KeyMsg{Type: tea.KeyHome}},
+		{ebiten.KeyNumpad1, shift, tea.KeyMsg{Type: tea.KeyShiftEnd}},
+		{ebiten.KeyNumpad9, none, tea.KeyMsg{Type: tea.KeyPgUp}},
+		{ebiten.KeyNumpad3, ctrl, tea.KeyMsg{Type: tea.KeyCtrlPgDown}},
+		{ebiten.KeyNumpad8, none, tea.KeyMsg{Type: tea.KeyUp}},
+		{ebiten.KeyNumpad4, ctrl, tea.KeyMsg{Type: tea.KeyCtrlLeft}},
+		{ebiten.KeyNumpad0, none, tea.KeyMsg{Type: tea.KeyInsert}},
+		{ebiten.KeyNumpadDecimal, none, tea.KeyMsg{Type: tea.KeyDelete}},
+		{ebiten.KeyF1, none, tea.KeyMsg{Type: tea.KeyF1}},
+		{ebiten.KeyF1, shift, tea.KeyMsg{Type: tea.KeyF13}},
+		{ebiten.KeyF8, shift, tea.KeyMsg{Type: tea.KeyF20}},
+		{ebiten.KeyF12, none, tea.KeyMsg{Type: tea.KeyF12}},
+		{ebiten.KeyC, ctrl, tea.KeyMsg{Type: tea.KeyCtrlC}},
+		{ebiten.KeyC, ctrlShift, tea.KeyMsg{Type: tea.KeyCtrlC}},
+		{ebiten.KeyX, ctrlAlt, tea.KeyMsg{Type: tea.KeyCtrlX, Alt: true}},
+		{ebiten.KeySpace, ctrl, tea.KeyMsg{Type: tea.KeyCtrlAt}},
+		{ebiten.KeySlash, ctrl, tea.KeyMsg{Type: tea.KeyCtrlUnderscore}},
+	}
+
+	for _, test := range tests {
+		isShift := test.mods == shift || test.mods == ctrlShift
+		isAlt := test.mods == alt || test.mods == ctrlAlt
+		isCtrl := test.mods == ctrl || test.mods == ctrlShift || test.mods == ctrlAlt
+
+		msg, ok := keyMsg(test.key, isShift, isAlt, isCtrl)
+		assert.True(t, ok, test.key.String())
+		assert.Equal(t, test.want, msg, test.key.String())
+	}
+}
+
+func TestKeyMsgIgnored(t *testing.T) {
+	// Modifiers on their own and keys that produce text are not sent as keys.
+	for _, key := range []ebiten.Key{ebiten.KeyShift, ebiten.KeyShiftLeft, ebiten.KeyControl, ebiten.KeyAlt, ebiten.KeyA, ebiten.KeySpace, ebiten.KeyNumpad5, ebiten.KeyNumpadAdd} {
+		_, ok := keyMsg(key, true, false, false)
+		assert.False(t, ok, key.String())
+	}
+}
+
+func TestRuneMsg(t *testing.T) {
+	assert.Equal(t, "a", runeMsg('a', false).String())
+	assert.Equal(t, "alt+A", runeMsg('A', true).String())
+	assert.Equal(t, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}, runeMsg(' ', false))
+}
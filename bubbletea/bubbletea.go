@@ -14,7 +14,9 @@ func init() {
 }
 
 // Window creates a new crt based bubbletea window with the given width, height, fonts, model and default background color.
-// Additional options can be passed to the bubbletea program.
+// Additional options can be passed to the bubbletea program. Mouse events are only sent to the
+// program if it enables them (e.g. with tea.WithMouseCellMotion()), otherwise the mouse wheel
+// scrolls through the scrollback.
 func Window(width int, height int, fonts crt.Fonts, model tea.Model, defaultBg color.Color, options ...tea.ProgramOption) (*crt.Window, *tea.Program, error) {
 	gameInput := crt.NewConcurrentRW()
 	gameOutput := crt.NewConcurrentRW()
@@ -25,7 +27,6 @@ func Window(width int, height int, fonts crt.Fonts, model tea.Model, defaultBg c
 	prog := tea.NewProgram(
 		model,
 		append([]tea.ProgramOption{
-			tea.WithMouseAllMotion(),
 			tea.WithInput(gameInput),
 			tea.WithOutput(gameOutput),
 			tea.WithANSICompressor(),
@@ -41,5 +42,12 @@ func Window(width int, height int, fonts crt.Fonts, model tea.Model, defaultBg c
 	}()
 
 	win, err := crt.NewGame(width, height, fonts, gameOutput, NewAdapter(prog), defaultBg)
-	return win, prog, err
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Replies to queries (e.g. of the clipboard) are sent to the program like the input.
+	win.SetResponseWriter(gameInput)
+
+	return win, prog, nil
 }
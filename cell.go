@@ -16,10 +16,137 @@ const (
 	FontWeightItalic
 )
 
+// CellAttributes is a set of text attributes of a terminal cell.
+type CellAttributes uint8
+
+const (
+	// AttrDim draws the text with a reduced intensity.
+	AttrDim CellAttributes = 1 << iota
+
+	// AttrUnderline draws a line below the text.
+	AttrUnderline
+
+	// AttrBlink lets the text blink.
+	AttrBlink
+
+	// AttrReverse swaps the foreground and background color.
+	AttrReverse
+
+	// AttrConceal hides the text.
+	AttrConceal
+
+	// AttrStrikethrough draws a line through the text.
+	AttrStrikethrough
+
+	// AttrOverline draws a line above the text.
+	AttrOverline
+
+	// attrWrapline marks the last cell of a line that was wrapped onto the next line.
+	attrWrapline
+)
+
+// CellColor is a compact color of a cell. It is either the default color of the terminal,
+// an index into the 256 color table or a true color. Default and indexed colors are
+// resolved when the cell is drawn, so they follow changes of the theme.
+type CellColor uint32
+
+const (
+	cellColorDefault CellColor = iota << 24
+	cellColorIndexed
+	cellColorRGB
+
+	cellColorKindMask  CellColor = 0xff << 24
+	cellColorValueMask CellColor = 0xffffff
+)
+
+// DefaultColor is the default foreground or background color of the terminal.
+const DefaultColor = cellColorDefault
+
+// IndexedColor returns a color of the 256 color table. The first 16 colors are taken from the palette.
+func IndexedColor(id uint8) CellColor {
+	return cellColorIndexed | CellColor(id)
+}
+
+// RGBColor returns a true color.
+func RGBColor(r, g, b uint8) CellColor {
+	return cellColorRGB | CellColor(r)<<16 | CellColor(g)<<8 | CellColor(b)
+}
+
+// ToCellColor converts a color to a true color. The alpha channel is dropped.
+func ToCellColor(c color.Color) CellColor {
+	r, g, b, _ := c.RGBA()
+	return RGBColor(uint8(r>>8), uint8(g>>8), uint8(b>>8))
+}
+
+// IsDefault returns true if the color is the default color.
+func (c CellColor) IsDefault() bool {
+	return c&cellColorKindMask == cellColorDefault
+}
+
+// Index returns the index into the 256 color table and true if the color is an indexed color.
+func (c CellColor) Index() (uint8, bool) {
+	return uint8(c), c&cellColorKindMask == cellColorIndexed
+}
+
+// RGB returns the components and true if the color is a true color.
+func (c CellColor) RGB() (uint8, uint8, uint8, bool) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c), c&cellColorKindMask == cellColorRGB
+}
+
 // GridCell is a single cell in the terminal grid.
 type GridCell struct {
-	Char   rune
-	Fg     color.Color
-	Bg     color.Color
-	Weight FontWeight
+	Char       rune
+	Fg         CellColor
+	Bg         CellColor
+	Weight     FontWeight
+	Attributes CellAttributes
+
+	// Link is the id of the hyperlink (OSC 8) of the cell or zero if the cell isn't linked.
+	Link uint16
+}
+
+// resolveColor returns the color a cell color is displayed with. Default colors are resolved
+// to the default foreground or background color depending on fg.
+func (g *Window) resolveColor(c CellColor, fg bool) color.Color {
+	switch c & cellColorKindMask {
+	case cellColorIndexed:
+		if col, ok := g.ansiColor(int(c & cellColorValueMask)); ok {
+			return col
+		}
+	case cellColorRGB:
+		r, gr, b, _ := c.RGB()
+		return color.RGBA{R: r, G: gr, B: b, A: 0xff}
+	}
+
+	if fg {
+		return g.defaultFg
+	}
+	return g.defaultBg
+}
+
+// cellColors returns the foreground and background color a cell is displayed with.
+func (g *Window) cellColors(cell GridCell) (color.Color, color.Color) {
+	var fg, bg color.Color
+	if cell.Attributes&AttrReverse != 0 {
+		fg, bg = g.resolveColor(cell.Bg, false), g.resolveColor(cell.Fg, true)
+	} else {
+		fg, bg = g.resolveColor(cell.Fg, true), g.resolveColor(cell.Bg, false)
+	}
+
+	if cell.Attributes&AttrDim != 0 {
+		fg = blendColors(fg, bg)
+	}
+	return fg, bg
+}
+
+// blendColors mixes two colors in equal parts.
+func blendColors(a, b color.Color) color.Color {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	return color.RGBA64{
+		R: uint16((ar + br) / 2),
+		G: uint16((ag + bg) / 2),
+		B: uint16((ab + bb) / 2),
+		A: 0xffff,
+	}
 }
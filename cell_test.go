@@ -0,0 +1,116 @@
+package crt
+
+import (
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"image/color"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+const (
+	benchWidth  = 200
+	benchHeight = 60
+)
+
+// legacyGridCell is the cell layout before colors were stored as CellColor.
+type legacyGridCell struct {
+	Char       rune
+	Fg         color.Color
+	Bg         color.Color
+	Weight     FontWeight
+	Attributes CellAttributes
+}
+
+func TestCellColor(t *testing.T) {
+	assert.True(t, DefaultColor.IsDefault())
+
+	id, ok := IndexedColor(196).Index()
+	assert.True(t, ok)
+	assert.Equal(t, uint8(196), id)
+	assert.False(t, IndexedColor(0).IsDefault())
+
+	r, g, b, ok := RGBColor(1, 2, 3).RGB()
+	assert.True(t, ok)
+	assert.Equal(t, []uint8{1, 2, 3}, []uint8{r, g, b})
+	assert.Equal(t, RGBColor(255, 0, 0), ToCellColor(color.RGBA{R: 255, A: 255}))
+
+	_, ok = RGBColor(1, 2, 3).Index()
+	assert.False(t, ok)
+
+	assert.LessOrEqual(t, int(unsafe.Sizeof(GridCell{})), 16)
+}
+
+func TestResolveColor(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+
+	assert.Equal(t, win.defaultFg, win.resolveColor(DefaultColor, true))
+	assert.Equal(t, win.defaultBg, win.resolveColor(DefaultColor, false))
+	assert.Equal(t, DefaultPalette[3], win.resolveColor(IndexedColor(3), true))
+	assert.Equal(t, color.Color(color.RGBA{R: 1, G: 2, B: 3, A: 255}), win.resolveColor(RGBColor(1, 2, 3), true))
+
+	// Palette changes apply to existing cells.
+	win.feed([]byte(termenv.CSI + "31mA"))
+	palette := win.GetPalette()
+	palette[1] = color.RGBA{R: 1, A: 255}
+	win.SetPalette(palette)
+
+	fg, _ := win.cellColors(win.grid[0][0])
+	assert.Equal(t, palette[1], fg)
+}
+
+func BenchmarkLegacyGridAlloc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		grid := make([][]legacyGridCell, benchHeight)
+		for y := range grid {
+			grid[y] = make([]legacyGridCell, benchWidth)
+			for x := range grid[y] {
+				grid[y][x] = legacyGridCell{Char: ' ', Fg: color.RGBA{R: 255, G: 255, B: 255, A: 255}, Bg: color.RGBA{A: 255}}
+			}
+		}
+	}
+}
+
+func BenchmarkGridAlloc(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		grid := make([][]GridCell, benchHeight)
+		for y := range grid {
+			grid[y] = make([]GridCell, benchWidth)
+			for x := range grid[y] {
+				grid[y][x] = GridCell{Char: ' ', Fg: DefaultColor, Bg: DefaultColor}
+			}
+		}
+	}
+}
+
+func BenchmarkFeedColoredScreen(b *testing.B) {
+	win := newTestWindow(b, benchWidth, benchHeight)
+
+	var sb strings.Builder
+	for y := 0; y < benchHeight; y++ {
+		for x := 0; x < benchWidth; x++ {
+			sb.WriteString(termenv.CSI + "38;5;" + string(rune('0'+x%8)) + "mx")
+		}
+	}
+	data := []byte(sb.String())
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		win.feed(data)
+	}
+}
+
+func BenchmarkRecalculateBackgrounds(b *testing.B) {
+	win := newTestWindow(b, benchWidth, benchHeight)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		win.RecalculateBackgrounds()
+	}
+}
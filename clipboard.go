@@ -0,0 +1,102 @@
+package crt
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// Clipboard is the clipboard the application can access with OSC 52.
+type Clipboard interface {
+	ReadAll() (string, error)
+	WriteAll(text string) error
+}
+
+// ClipboardPolicy controls how the application can access the clipboard.
+type ClipboardPolicy uint8
+
+const (
+	// ClipboardDeny ignores all clipboard requests.
+	ClipboardDeny ClipboardPolicy = 0
+
+	// ClipboardRead allows the application to query the clipboard.
+	ClipboardRead ClipboardPolicy = 1 << 0
+
+	// ClipboardWrite allows the application to set the clipboard.
+	ClipboardWrite ClipboardPolicy = 1 << 1
+
+	// ClipboardReadWrite allows the application to query and set the clipboard.
+	ClipboardReadWrite = ClipboardRead | ClipboardWrite
+)
+
+// WithClipboard sets the clipboard the application can access with OSC 52.
+func WithClipboard(clipboard Clipboard) WindowOption {
+	return func(window *Window) {
+		window.SetClipboard(clipboard)
+	}
+}
+
+// WithClipboardPolicy sets how the application can access the clipboard.
+func WithClipboardPolicy(policy ClipboardPolicy) WindowOption {
+	return func(window *Window) {
+		window.SetClipboardPolicy(policy)
+	}
+}
+
+// SetClipboard sets the clipboard the application can access with OSC 52. By default this is the system clipboard.
+func (g *Window) SetClipboard(clipboard Clipboard) {
+	g.clipboard = clipboard
+}
+
+// SetClipboardPolicy sets how the application can access the clipboard. By default the application
+// can only set the clipboard, because reading it would leak whatever the user copied last.
+func (g *Window) SetClipboardPolicy(policy ClipboardPolicy) {
+	g.clipboardPolicy = policy
+}
+
+// handleClipboard handles OSC 52 (selection;data). The data is either the base64 encoded text
+// that is copied or "?" to query the clipboard. The system clipboard can be slow (e.g. xclip
+// on Linux), so it is accessed on the goroutine that writes the replies to the application.
+func (g *Window) handleClipboard(data string) {
+	if g.clipboard == nil {
+		return
+	}
+
+	selection, payload, ok := strings.Cut(data, ";")
+	if !ok {
+		return
+	}
+	if selection == "" {
+		selection = "c"
+	}
+
+	if payload == "?" {
+		if g.clipboardPolicy&ClipboardRead == 0 {
+			return
+		}
+
+		clipboard := g.clipboard
+		g.responses.push(func() string {
+			// If the clipboard can't be read the reply is empty, so the application doesn't wait for it.
+			text, err := clipboard.ReadAll()
+			if err != nil {
+				text = ""
+			}
+			return ESC + "]52;" + selection + ";" + base64.StdEncoding.EncodeToString([]byte(text)) + ESC + "\\"
+		})
+		return
+	}
+
+	if g.clipboardPolicy&ClipboardWrite == 0 {
+		return
+	}
+
+	text, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return
+	}
+	clipboard := g.clipboard
+	g.responses.push(func() string {
+		_ = clipboard.WriteAll(string(text))
+		return ""
+	})
+}
@@ -0,0 +1,27 @@
+package crt
+
+import "sync"
+
+// SystemClipboard keeps the text in memory, because the clipboard of the browser can't be read
+// outside of user events. The text is shared by all windows.
+type SystemClipboard struct{}
+
+var memoryClipboard struct {
+	sync.Mutex
+	text string
+}
+
+// ReadAll returns the text of the clipboard.
+func (SystemClipboard) ReadAll() (string, error) {
+	memoryClipboard.Lock()
+	defer memoryClipboard.Unlock()
+	return memoryClipboard.text, nil
+}
+
+// WriteAll replaces the text of the clipboard.
+func (SystemClipboard) WriteAll(text string) error {
+	memoryClipboard.Lock()
+	defer memoryClipboard.Unlock()
+	memoryClipboard.text = text
+	return nil
+}
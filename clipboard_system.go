@@ -0,0 +1,19 @@
+//go:build !js
+// +build !js
+
+package crt
+
+import "github.com/atotto/clipboard"
+
+// SystemClipboard is the clipboard of the operating system.
+type SystemClipboard struct{}
+
+// ReadAll returns the text of the clipboard.
+func (SystemClipboard) ReadAll() (string, error) {
+	return clipboard.ReadAll()
+}
+
+// WriteAll replaces the text of the clipboard.
+func (SystemClipboard) WriteAll(text string) error {
+	return clipboard.WriteAll(text)
+}
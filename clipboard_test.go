@@ -0,0 +1,110 @@
+package crt
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type testClipboard struct {
+	text string
+	err  error
+}
+
+func (c *testClipboard) ReadAll() (string, error) {
+	return c.text, c.err
+}
+
+func (c *testClipboard) WriteAll(text string) error {
+	c.text = text
+	return c.err
+}
+
+func TestClipboardWrite(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	clip := &testClipboard{}
+	win.SetClipboard(clip)
+
+	win.feed([]byte(ESC + "]52;c;aGVsbG8gd29ybGQ=\a"))
+	win.responses.wait()
+	assert.Equal(t, "hello world", clip.text)
+
+	// Invalid data is ignored.
+	win.feed([]byte(ESC + "]52;c;not base64!\a"))
+	win.responses.wait()
+	assert.Equal(t, "hello world", clip.text)
+
+	assert.Equal(t, []string{"", ""}, screenLines(win))
+}
+
+func TestClipboardQuery(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	clip := &testClipboard{text: "secret"}
+	var response bytes.Buffer
+	win.SetClipboard(clip)
+	win.SetResponseWriter(&response)
+
+	// Reading is denied by default.
+	win.feed([]byte(ESC + "]52;c;?\a"))
+	win.responses.wait()
+	assert.Equal(t, "", response.String())
+
+	win.SetClipboardPolicy(ClipboardReadWrite)
+	win.feed([]byte(ESC + "]52;;?" + ESC + "\\"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"]52;c;c2VjcmV0"+ESC+"\\", response.String())
+
+	response.Reset()
+	clip.err = errors.New("unavailable")
+	win.feed([]byte(ESC + "]52;p;?\a"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"]52;p;"+ESC+"\\", response.String())
+}
+
+func TestClipboardPolicy(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	clip := &testClipboard{text: "old"}
+	var response bytes.Buffer
+	win.SetClipboard(clip)
+	win.SetResponseWriter(&response)
+
+	win.SetClipboardPolicy(ClipboardDeny)
+	win.feed([]byte(ESC + "]52;c;bmV3\a" + ESC + "]52;c;?\a"))
+	win.responses.wait()
+	assert.Equal(t, "old", clip.text)
+	assert.Equal(t, "", response.String())
+
+	win.SetClipboardPolicy(ClipboardRead)
+	win.feed([]byte(ESC + "]52;c;bmV3\a" + ESC + "]52;c;?\a"))
+	win.responses.wait()
+	assert.Equal(t, "old", clip.text)
+	assert.Equal(t, ESC+"]52;c;b2xk"+ESC+"\\", response.String())
+}
+
+type blockingClipboard struct {
+	testClipboard
+	release chan struct{}
+}
+
+func (c *blockingClipboard) ReadAll() (string, error) {
+	<-c.release
+	return c.testClipboard.ReadAll()
+}
+
+func TestClipboardDoesntBlock(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	clip := &blockingClipboard{testClipboard: testClipboard{text: "slow"}, release: make(chan struct{})}
+	var response bytes.Buffer
+	win.SetClipboard(clip)
+	win.SetClipboardPolicy(ClipboardReadWrite)
+	win.SetResponseWriter(&response)
+
+	// The query waits for the clipboard, but the terminal goes on and the replies keep their order.
+	win.feed([]byte(ESC + "]52;c;?\a" + ESC + "[5n" + "ok"))
+	assert.Equal(t, []string{"ok", ""}, screenLines(win))
+
+	close(clip.release)
+	win.responses.wait()
+	assert.Equal(t, ESC+"]52;c;c2xvdw=="+ESC+"\\"+ESC+"[0n", response.String())
+}
@@ -0,0 +1,107 @@
+package crt
+
+// tabWidth is the distance of the default tab stops.
+const tabWidth = 8
+
+// SetOnBell sets a function that is called when the application rings the bell (BEL).
+// Bells that follow each other closely are dropped, so the function isn't flooded. A nil
+// function removes the callback.
+func (g *Window) SetOnBell(fn func()) {
+	if fn == nil {
+		fn = func() {}
+	}
+	g.onBell = fn
+}
+
+// SetNewLineMode enables or disables the implicit carriage return on line feeds like LNM.
+// It is enabled by default, because the output of the application isn't passed through
+// a tty that translates "\n" to "\r\n".
+func (g *Window) SetNewLineMode(val bool) {
+	g.newLineMode = val
+}
+
+// handleControl executes a C0 control character.
+func (g *Window) handleControl(code byte) {
+	switch code {
+	case '\a': // BEL
+		g.ring()
+	case '\b': // BS
+		g.cursorX = g.clampedCursorX() - 1
+		if g.cursorX < 0 {
+			g.cursorX = 0
+		}
+	case '\t': // HT
+		g.tabForward(1)
+	case '\n', '\v', '\f': // LF, VT, FF
+		g.cursorX = g.clampedCursorX()
+		g.lineFeed()
+		if g.newLineMode {
+			g.cursorX = 0
+		}
+	case '\r': // CR
+		g.cursorX = 0
+	default:
+		return
+	}
+
+	g.InvalidateBuffer()
+}
+
+// setMode sets an ANSI mode.
+func (g *Window) setMode(mode int, val bool) {
+	switch mode {
+	case 20: // LNM
+		g.newLineMode = val
+	}
+}
+
+// newTabStops creates a tab stop table with a stop every tabWidth cells.
+func newTabStops(width int) []bool {
+	return resizeTabStops(nil, width)
+}
+
+// resizeTabStops adapts a tab stop table to a new width. New cells get the default tab stops.
+func resizeTabStops(stops []bool, width int) []bool {
+	resized := make([]bool, width)
+	n := copy(resized, stops)
+	for x := n; x < width; x++ {
+		resized[x] = x > 0 && x%tabWidth == 0
+	}
+	return resized
+}
+
+// tabForward moves the cursor to the n-th next tab stop or the last column.
+func (g *Window) tabForward(n int) {
+	x := g.clampedCursorX()
+	for n > 0 && x < g.cellsWidth-1 {
+		x++
+		if g.tabStops[x] {
+			n--
+		}
+	}
+	g.cursorX = x
+}
+
+// tabBackward moves the cursor to the n-th previous tab stop or the first column.
+func (g *Window) tabBackward(n int) {
+	x := g.clampedCursorX()
+	for n > 0 && x > 0 {
+		x--
+		if g.tabStops[x] {
+			n--
+		}
+	}
+	g.cursorX = x
+}
+
+// clearTabStops clears the tab stop at the cursor (0) or all tab stops (3) like TBC.
+func (g *Window) clearTabStops(mode int) {
+	switch mode {
+	case 0:
+		g.tabStops[g.clampedCursorX()] = false
+	case 3:
+		for x := range g.tabStops {
+			g.tabStops[x] = false
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestControlCharacters(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		input   string
+		lines   []string
+		cursorX int
+		cursorY int
+	}{
+		{"carriage return", "50%\r100%", []string{"100%", "", ""}, 4, 0},
+		{"backspace", "abc\b\bX", []string{"aXc", "", ""}, 2, 0},
+		{"backspace at start", "\bX", []string{"X", "", ""}, 1, 0},
+		{"backspace after last column", "abcdefghij\bX", []string{"abcdefghXj", "", ""}, 9, 0},
+		{"tab", "a\tb", []string{"a       b", "", ""}, 9, 0},
+		{"tab to last column", "\t\t\tX", []string{"         X", "", ""}, 10, 0},
+		{"line feed", "ab\ncd", []string{"ab", "cd", ""}, 2, 1},
+		{"vertical tab and form feed", "a\vb\fc", []string{"a", "b", "c"}, 1, 2},
+		{"line feed without new line mode", termenv.CSI + "20l" + "ab\ncd\r\ne", []string{"ab", "  cd", "e"}, 1, 2},
+		{"bell is not printed", "a\ab", []string{"ab", "", ""}, 2, 0},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			win := newTestWindow(t, 10, 3)
+			win.feed([]byte(test.input))
+
+			assert.Equal(t, test.lines, screenLines(win))
+			assert.Equal(t, test.cursorX, win.cursorX)
+			assert.Equal(t, test.cursorY, win.cursorY)
+		})
+	}
+}
+
+func TestTabStops(t *testing.T) {
+	win := newTestWindow(t, 20, 2)
+
+	// HTS sets a stop at the cursor, CHT and CBT move between stops.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorHorizontalSeq, 4) + ESC + "H"))
+	win.feed([]byte("\r\t"))
+	assert.Equal(t, 3, win.cursorX)
+	win.feed([]byte(termenv.CSI + "2I"))
+	assert.Equal(t, 16, win.cursorX)
+	win.feed([]byte(termenv.CSI + "Z"))
+	assert.Equal(t, 8, win.cursorX)
+
+	// TBC 0 clears the stop at the cursor, TBC 3 all stops.
+	win.feed([]byte(termenv.CSI + "g" + "\r\t\t"))
+	assert.Equal(t, 16, win.cursorX)
+	win.feed([]byte(termenv.CSI + "3g" + "\r\t"))
+	assert.Equal(t, 19, win.cursorX)
+
+	// Resizing keeps the stops and adds the default stops.
+	win.Resize(30, 2)
+	win.feed([]byte("\r\t"))
+	assert.Equal(t, 24, win.cursorX)
+}
+
+func TestBell(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.SetVisualBell(true)
+
+	rings := 0
+	win.SetOnBell(func() {
+		rings++
+	})
+
+	// A flood of bells only rings once. BEL also terminates OSC sequences, which must not ring the bell.
+	win.feed([]byte("\a\a\a" + ESC + "]0;title\a"))
+	assert.Equal(t, 1, rings)
+	assert.True(t, win.visualBellUntil.After(time.Now()))
+
+	// After the interval the bell rings again.
+	win.lastBell = win.lastBell.Add(-bellInterval)
+	win.feed([]byte("\a"))
+	assert.Equal(t, 2, rings)
+
+	// Without a callback the bell is still handled.
+	win.SetOnBell(nil)
+	win.lastBell = win.lastBell.Add(-bellInterval)
+	assert.NotPanics(t, func() { win.feed([]byte("\a")) })
+}
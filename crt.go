@@ -7,18 +7,16 @@ import (
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
 	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/text"
-	"github.com/lucasb-eyer/go-colorful"
-	"github.com/muesli/ansi"
-	"github.com/muesli/termenv"
+	"github.com/hajimehoshi/ebiten/v2/vector"
 	"image"
 	"image/color"
 	"io"
 	"sync"
-	"unicode/utf8"
+	"time"
 )
 
-// colorCache is the ansi color cache.
-var colorCache = map[int]color.Color{}
+// blinkInterval is the time in milliseconds blinking text is shown or hidden.
+const blinkInterval = 500
 
 type Window struct {
 	sync.Mutex
@@ -36,25 +34,99 @@ type Window struct {
 	tty          io.Reader
 
 	// Terminal cursor and color states.
-	cursorChar  string
-	cursorColor color.Color
-	showCursor  bool
-	cursorX     int
-	cursorY     int
-	mouseCellX  int
-	mouseCellY  int
-	defaultBg   color.Color
-	curFg       color.Color
-	curBg       color.Color
-	curWeight   FontWeight
+	cursorChar     string
+	cursorColor    color.Color
+	selectionColor color.Color
+	showCursor     bool
+	cursorX        int
+	cursorY        int
+	mouseCellX     int
+	mouseCellY     int
+	defaultFg      color.Color
+	defaultBg      color.Color
+	palette        Palette
+	colorOverrides map[int]color.Color
+	savedColors    map[int]color.Color
+	curFg          CellColor
+	curBg          CellColor
+	curWeight      FontWeight
+	curAttrs       CellAttributes
+
+	// Operating system commands
+	oscHandlers      map[int]OSCHandler
+	title            string
+	iconName         string
+	workingDirectory string
+
+	// Replies to queries of the application and clipboard access (OSC 52)
+	responses       responseQueue
+	identity        string
+	clipboard       Clipboard
+	clipboardPolicy ClipboardPolicy
+
+	// Hyperlinks (OSC 8)
+	links          linkTable
+	curLink        uint16
+	hoverLink      uint16
+	linkPressed    bool
+	onLinkActivate func(url string)
+
+	// Bell
+	visualBell      bool
+	lastBell        time.Time
+	visualBellUntil time.Time
+
+	// Line feed implies carriage return (LNM) and tab stops.
+	newLineMode bool
+	tabStops    []bool
+
+	// Scrolling region (inclusive, zero based), origin mode and autowrap (DECAWM).
+	scrollTop    int
+	scrollBottom int
+	originMode   bool
+	autowrap     bool
+
+	// Cursor state saved by DECSC / SCOSC.
+	savedCursor *savedCursor
+
+	// Alternate screen buffer. The inactive screen holds the buffer that is currently not shown.
+	altScreen      bool
+	inactiveScreen screen
+
+	// Lines scrolled off the primary screen and how far the view is scrolled back into them.
+	scrollback *scrollback
+	viewOffset int
+	viewBg     *image.RGBA
+	wheelDelta float64
+
+	// Grapheme clusters of multiple runes that are stored in the cells.
+	graphemes graphemeTable
+
+	// Resizing and zoom. The window size is in pixels and can be larger than the cells.
+	noReflow     bool
+	zoomMode     ZoomMode
+	noZoomKeys   bool
+	baseFonts    Fonts
+	windowWidth  int
+	windowHeight int
+
+	// Mouse tracking requested by the application (e.g. ?1000h) and the SGR encoding (?1006h).
+	mouseMode MouseMode
+	mouseSGR  bool
 
 	// Callbacks
 	onUpdate   func()
+	onBell     func()
 	onPreDraw  func(screen *ebiten.Image)
 	onPostDraw func(screen *ebiten.Image)
 
+	// Cell that was printed last, so following combining characters can be added to it.
+	lastCell *GridCell
+
 	// Other
 	seqBuffer        []byte
+	sgrBuffer        []any
+	parser           *Parser
 	showTps          bool
 	fonts            Fonts
 	bgColors         *image.RGBA
@@ -64,6 +136,8 @@ type Window struct {
 	shaderBuffer     *ebiten.Image
 	lastBuffer       *ebiten.Image
 	invalidateBuffer bool
+	hasBlink         bool
+	blinkVisible     bool
 }
 
 type WindowOption func(window *Window)
@@ -74,15 +148,11 @@ func NewGame(width int, height int, fonts Fonts, tty io.Reader, adapter InputAda
 		defaultBg = color.Black
 	}
 
-	bounds, _, _ := fonts.Normal.GlyphBounds([]rune("█")[0])
-	size := bounds.Max.Sub(bounds.Min)
-
-	cellWidth := size.X.Ceil()
-	cellHeight := size.Y.Ceil()
-	cellOffsetY := -bounds.Min.Y.Ceil()
+	cellWidth, cellHeight, cellOffsetY := cellMetrics(fonts)
 
-	cellsWidth := int(float64(width)*DeviceScale()) / cellWidth
-	cellsHeight := int(float64(height)*DeviceScale()) / cellHeight
+	windowWidth, windowHeight := int(float64(width)*DeviceScale()), int(float64(height)*DeviceScale())
+	cellsWidth := windowWidth / cellWidth
+	cellsHeight := windowHeight / cellHeight
 
 	grid := make([][]GridCell, cellsHeight)
 	for y := 0; y < cellsHeight; y++ {
@@ -90,8 +160,8 @@ func NewGame(width int, height int, fonts Fonts, tty io.Reader, adapter InputAda
 		for x := 0; x < cellsWidth; x++ {
 			grid[y][x] = GridCell{
 				Char:   ' ',
-				Fg:     color.White,
-				Bg:     defaultBg,
+				Fg:     DefaultColor,
+				Bg:     DefaultColor,
 				Weight: FontWeightNormal,
 			}
 		}
@@ -104,26 +174,47 @@ func NewGame(width int, height int, fonts Fonts, tty io.Reader, adapter InputAda
 		cellWidth:        cellWidth,
 		cellHeight:       cellHeight,
 		cellOffsetY:      cellOffsetY,
+		scrollBottom:     cellsHeight - 1,
 		fonts:            fonts,
+		baseFonts:        fonts,
+		windowWidth:      windowWidth,
+		windowHeight:     windowHeight,
+		defaultFg:        DefaultTheme.Foreground,
 		defaultBg:        defaultBg,
+		palette:          DefaultPalette,
 		grid:             grid,
+		scrollback:       newScrollback(DefaultScrollbackSize),
+		graphemes:        graphemeTable{limit: minGraphemeLimit},
+		links:            linkTable{limit: minLinkLimit},
 		tty:              tty,
 		bgColors:         image.NewRGBA(image.Rect(0, 0, cellsWidth*cellWidth, cellsHeight*cellHeight)),
 		lastBuffer:       ebiten.NewImage(cellsWidth*cellWidth, cellsHeight*cellHeight),
 		cursorChar:       "█",
-		cursorColor:      color.RGBA{R: 255, G: 255, B: 255, A: 100},
+		cursorColor:      DefaultTheme.Cursor,
+		selectionColor:   DefaultTheme.Selection,
 		onUpdate:         func() {},
+		onBell:           func() {},
+		onLinkActivate:   func(url string) {},
+		clipboard:        SystemClipboard{},
+		clipboardPolicy:  ClipboardWrite,
+		identity:         DefaultIdentity,
+		newLineMode:      true,
+		autowrap:         true,
+		tabStops:         newTabStops(cellsWidth),
 		onPreDraw:        func(screen *ebiten.Image) {},
 		onPostDraw:       func(screen *ebiten.Image) {},
 		invalidateBuffer: true,
+		blinkVisible:     true,
 		seqBuffer:        make([]byte, 0, 2^12),
+		parser:           NewParser(),
 	}
 
 	game.inputAdapter.HandleWindowSize(WindowSize{
-		Width:  cellsWidth - 1,
+		Width:  cellsWidth,
 		Height: cellsHeight,
 	})
 
+	game.registerDefaultOSCHandlers()
 	game.ResetSGR()
 	game.RecalculateBackgrounds()
 
@@ -180,31 +271,47 @@ func (g *Window) InvalidateBuffer() {
 
 // ResetSGR resets the SGR attributes to their default values.
 func (g *Window) ResetSGR() {
-	g.curFg = color.White
-	g.curBg = g.defaultBg
+	g.curFg = DefaultColor
+	g.curBg = DefaultColor
 	g.curWeight = FontWeightNormal
+	g.curAttrs = 0
 }
 
 // SetBgPixels sets a chunk of background pixels in the size of the cell.
 func (g *Window) SetBgPixels(x, y int, c color.Color) {
-	for i := 0; i < g.cellWidth; i++ {
-		for j := 0; j < g.cellHeight; j++ {
-			g.bgColors.Set(x*g.cellWidth+i, y*g.cellHeight+j, c)
+	fillCellPixels(g.bgColors, x, y, g.cellWidth, g.cellHeight, c)
+	g.InvalidateBuffer()
+}
+
+// fillCellPixels fills the pixels of a cell in the image with a color.
+func fillCellPixels(img *image.RGBA, x, y, cellWidth, cellHeight int, c color.Color) {
+	r, g, b, a := c.RGBA()
+	pixel := [4]byte{byte(r >> 8), byte(g >> 8), byte(b >> 8), byte(a >> 8)}
+
+	for j := 0; j < cellHeight; j++ {
+		offset := img.PixOffset(x*cellWidth, y*cellHeight+j)
+		row := img.Pix[offset : offset+4*cellWidth]
+		for i := 0; i < len(row); i += 4 {
+			copy(row[i:i+4], pixel[:])
 		}
 	}
-	g.InvalidateBuffer()
+}
+
+// syncBgPixels sets the background pixels of a cell to the background color the cell is displayed with.
+func (g *Window) syncBgPixels(x, y int) {
+	_, bg := g.cellColors(g.grid[y][x])
+	g.SetBgPixels(x, y, bg)
 }
 
 // SetBg sets the background color of a cell and checks if it needs to be redrawn.
 func (g *Window) SetBg(x, y int, c color.Color) {
-	ra, rg, rb, _ := g.grid[y][x].Bg.RGBA()
-	ca, cg, cb, _ := c.RGBA()
-	if ra == ca && rg == cg && rb == cb {
+	bg := ToCellColor(c)
+	if g.grid[y][x].Bg == bg {
 		return
 	}
 
-	g.SetBgPixels(x, y, c)
-	g.grid[y][x].Bg = c
+	g.grid[y][x].Bg = bg
+	g.syncBgPixels(x, y)
 }
 
 // GetCellsWidth returns the number of cells in the x direction.
@@ -220,105 +327,189 @@ func (g *Window) GetCellsHeight() int {
 func (g *Window) handleCSI(csi any) {
 	switch seq := csi.(type) {
 	case CursorUpSeq:
-		g.cursorY -= seq.Count
-		if g.cursorY < 0 {
-			g.cursorY = 0
+		top := 0
+		if g.cursorY >= g.scrollTop {
+			top = g.scrollTop
+		}
+
+		g.cursorY -= countOrOne(seq.Count)
+		if g.cursorY < top {
+			g.cursorY = top
 		}
 	case CursorDownSeq:
-		g.cursorY += seq.Count
-		if g.cursorY >= g.cellsHeight {
-			g.cursorY = g.cellsHeight - 1
+		bottom := g.cellsHeight - 1
+		if g.cursorY <= g.scrollBottom {
+			bottom = g.scrollBottom
+		}
+
+		g.cursorY += countOrOne(seq.Count)
+		if g.cursorY > bottom {
+			g.cursorY = bottom
 		}
 	case CursorForwardSeq:
-		g.cursorX += seq.Count
+		g.cursorX += countOrOne(seq.Count)
 		if g.cursorX >= g.cellsWidth {
 			g.cursorX = g.cellsWidth - 1
 		}
 	case CursorBackSeq:
-		g.cursorX -= seq.Count
+		g.cursorX = g.clampedCursorX() - countOrOne(seq.Count)
 		if g.cursorX < 0 {
 			g.cursorX = 0
 		}
 	case CursorNextLineSeq:
-		g.cursorY += seq.Count
+		g.cursorY += countOrOne(seq.Count)
 		if g.cursorY >= g.cellsHeight {
 			g.cursorY = g.cellsHeight - 1
 		}
 		g.cursorX = 0
 	case CursorPreviousLineSeq:
-		g.cursorY -= seq.Count
+		g.cursorY -= countOrOne(seq.Count)
 		if g.cursorY < 0 {
 			g.cursorY = 0
 		}
 		g.cursorX = 0
 	case CursorHorizontalSeq:
-		g.cursorX = seq.Count - 1
+		g.cursorX = countOrOne(seq.Count) - 1
+		if g.cursorX >= g.cellsWidth {
+			g.cursorX = g.cellsWidth - 1
+		}
 	case CursorPositionSeq:
 		g.cursorX = seq.Col - 1
 		g.cursorY = seq.Row - 1
 
+		top, bottom := 0, g.cellsHeight-1
+		if g.originMode {
+			g.cursorY += g.scrollTop
+			top, bottom = g.scrollTop, g.scrollBottom
+		}
+
 		if g.cursorX < 0 {
 			g.cursorX = 0
 		} else if g.cursorX >= g.cellsWidth {
 			g.cursorX = g.cellsWidth - 1
 		}
 
-		if g.cursorY < 0 {
-			g.cursorY = 0
-		} else if g.cursorY >= g.cellsHeight {
-			g.cursorY = g.cellsHeight - 1
+		if g.cursorY < top {
+			g.cursorY = top
+		} else if g.cursorY > bottom {
+			g.cursorY = bottom
 		}
 	case EraseDisplaySeq:
-		if seq.Type != 2 {
-			return // only support 2 (erase entire display)
-		}
-
-		for i := 0; i < g.cellsWidth; i++ {
-			for j := 0; j < g.cellsHeight; j++ {
-				g.grid[j][i].Char = ' '
-				g.grid[j][i].Fg = color.White
-				g.grid[j][i].Bg = g.defaultBg
-			}
-		}
+		g.EraseDisplay(seq.Type)
 	case EraseLineSeq:
-		switch seq.Type {
-		case 0: // erase from cursor to end of line
-			for i := g.cursorX; i < g.cellsWidth-g.cursorX; i++ {
-				g.grid[g.cursorY][g.cursorX+i].Char = ' '
-				g.grid[g.cursorY][g.cursorX+i].Fg = color.White
-				g.SetBg(g.cursorX+i, g.cursorY, g.defaultBg)
-			}
-		case 1: // erase from start of line to cursor
-			for i := 0; i < g.cursorX; i++ {
-				g.grid[g.cursorY][i].Char = ' '
-				g.grid[g.cursorY][i].Fg = color.White
-				g.SetBg(i, g.cursorY, g.defaultBg)
-			}
-		case 2: // erase entire line
-			for i := 0; i < g.cellsWidth; i++ {
-				g.grid[g.cursorY][i].Char = ' '
-				g.grid[g.cursorY][i].Fg = color.White
-				g.SetBg(i, g.cursorY, g.defaultBg)
-			}
-		}
+		g.EraseLine(seq.Type)
+	case EraseCharacterSeq:
+		x := g.clampedCursorX()
+		g.eraseCells(g.cursorY, x, x+countOrOne(seq.Count))
+	case InsertCharacterSeq:
+		g.insertCells(g.cursorY, g.clampedCursorX(), countOrOne(seq.Count))
+	case DeleteCharacterSeq:
+		g.deleteCells(g.cursorY, g.clampedCursorX(), countOrOne(seq.Count))
 	case CursorShowSeq:
 		g.SetShowCursor(true)
 	case CursorHideSeq:
 		g.SetShowCursor(false)
 	case ScrollUpSeq:
-		fmt.Println("UNSUPPORTED: ScrollUpSeq", seq.Count)
+		g.saveScrollback(g.scrollTop, countOrOne(seq.Count))
+		g.scrollUp(g.scrollTop, g.scrollBottom, countOrOne(seq.Count))
 	case ScrollDownSeq:
-		fmt.Println("UNSUPPORTED: ScrollDownSeq", seq.Count)
+		g.scrollDown(g.scrollTop, g.scrollBottom, countOrOne(seq.Count))
 	case SaveCursorPositionSeq:
-		fmt.Println("UNSUPPORTED: SaveCursorPositionSeq")
+		g.SaveCursor()
 	case RestoreCursorPositionSeq:
-		fmt.Println("UNSUPPORTED: RestoreCursorPositionSeq")
+		g.RestoreCursor()
 	case ChangeScrollingRegionSeq:
-		fmt.Println("UNSUPPORTED: ChangeScrollingRegionSeq")
+		g.SetScrollingRegion(seq.Top, seq.Bottom)
+	case SetPrivateModeSeq:
+		for i := range seq.Modes {
+			g.setPrivateMode(seq.Modes[i], true)
+		}
+	case ResetPrivateModeSeq:
+		for i := range seq.Modes {
+			g.setPrivateMode(seq.Modes[i], false)
+		}
+	case SetModeSeq:
+		for i := range seq.Modes {
+			g.setMode(seq.Modes[i], true)
+		}
+	case ResetModeSeq:
+		for i := range seq.Modes {
+			g.setMode(seq.Modes[i], false)
+		}
+	case SetTabStopSeq:
+		g.tabStops[g.clampedCursorX()] = true
+	case TabClearSeq:
+		g.clearTabStops(seq.Type)
+	case CursorForwardTabSeq:
+		g.tabForward(countOrOne(seq.Count))
+	case CursorBackwardTabSeq:
+		g.tabBackward(countOrOne(seq.Count))
 	case InsertLineSeq:
-		fmt.Println("UNSUPPORTED: InsertLineSeq")
+		if g.cursorY < g.scrollTop || g.cursorY > g.scrollBottom {
+			return
+		}
+
+		g.scrollDown(g.cursorY, g.scrollBottom, countOrOne(seq.Count))
+		g.cursorX = 0
 	case DeleteLineSeq:
-		fmt.Println("UNSUPPORTED: DeleteLineSeq")
+		if g.cursorY < g.scrollTop || g.cursorY > g.scrollBottom {
+			return
+		}
+
+		g.scrollUp(g.cursorY, g.scrollBottom, countOrOne(seq.Count))
+		g.cursorX = 0
+	case DeviceStatusReportSeq:
+		g.reportStatus(seq.Type, seq.Private)
+	case PrimaryDeviceAttributesSeq:
+		g.respond(primaryDeviceAttributes)
+	case SecondaryDeviceAttributesSeq:
+		g.respond(secondaryDeviceAttributes)
+	case TerminalVersionSeq:
+		g.respond(ESC + "P>|" + g.identity + ESC + "\\")
+	case RequestModeSeq:
+		g.reportMode(seq.Mode, seq.Private)
+	}
+}
+
+func (g *Window) setPrivateMode(mode int, val bool) {
+	switch mode {
+	case 6: // DECOM
+		g.originMode = val
+		g.cursorHome()
+	case 7: // DECAWM
+		g.autowrap = val
+	case 25: // DECTCEM
+		g.SetShowCursor(val)
+	case 47: // Alternate screen buffer
+		if val {
+			g.enterAltScreen(false)
+		} else {
+			g.leaveAltScreen(false)
+		}
+	case 1047: // Alternate screen buffer, cleared when leaving
+		if val {
+			g.enterAltScreen(false)
+		} else {
+			g.leaveAltScreen(true)
+		}
+	case 1048: // Save / restore cursor
+		if val {
+			g.SaveCursor()
+		} else {
+			g.RestoreCursor()
+		}
+	case 1049: // Save cursor and switch to the cleared alternate screen buffer
+		if val {
+			g.SaveCursor()
+			g.enterAltScreen(true)
+		} else {
+			g.leaveAltScreen(false)
+			g.RestoreCursor()
+		}
+	case 9, 1000, 1002, 1003: // Mouse tracking
+		g.setMouseMode(mouseModes[mode], val)
+	case 1006: // SGR mouse encoding
+		g.mouseSGR = val
 	}
 }
 
@@ -332,68 +523,99 @@ func (g *Window) handleSGR(sgr any) {
 		g.curWeight = FontWeightItalic
 	case SGRUnsetBold:
 		g.curWeight = FontWeightNormal
+		g.curAttrs &^= AttrDim
 	case SGRUnsetItalic:
 		g.curWeight = FontWeightNormal
+	case SGRDim:
+		g.curAttrs |= AttrDim
+	case SGRUnderline:
+		g.curAttrs |= AttrUnderline
+	case SGRUnsetUnderline:
+		g.curAttrs &^= AttrUnderline
+	case SGRBlink:
+		g.curAttrs |= AttrBlink
+	case SGRUnsetBlink:
+		g.curAttrs &^= AttrBlink
+	case SGRReverse:
+		g.curAttrs |= AttrReverse
+	case SGRUnsetReverse:
+		g.curAttrs &^= AttrReverse
+	case SGRConceal:
+		g.curAttrs |= AttrConceal
+	case SGRUnsetConceal:
+		g.curAttrs &^= AttrConceal
+	case SGRStrikethrough:
+		g.curAttrs |= AttrStrikethrough
+	case SGRUnsetStrikethrough:
+		g.curAttrs &^= AttrStrikethrough
+	case SGROverline:
+		g.curAttrs |= AttrOverline
+	case SGRUnsetOverline:
+		g.curAttrs &^= AttrOverline
 	case SGRFgTrueColor:
-		g.curFg = color.RGBA{R: seq.R, G: seq.G, B: seq.B, A: 255}
+		g.curFg = RGBColor(seq.R, seq.G, seq.B)
 	case SGRBgTrueColor:
-		g.curBg = color.RGBA{R: seq.R, G: seq.G, B: seq.B, A: 255}
+		g.curBg = RGBColor(seq.R, seq.G, seq.B)
 	case SGRFgColor:
-		if val, ok := colorCache[seq.Id]; ok {
-			g.curFg = val
-		} else {
-			if col, err := colorful.Hex(termenv.ANSI256Color(seq.Id).String()); err == nil {
-				g.curFg = col
-				colorCache[seq.Id] = col
-			}
-		}
+		g.curFg = IndexedColor(uint8(seq.Id))
 	case SGRBgColor:
-		if val, ok := colorCache[seq.Id]; ok {
-			g.curBg = val
-		} else {
-			if col, err := colorful.Hex(termenv.ANSI256Color(seq.Id).String()); err == nil {
-				g.curBg = col
-				colorCache[seq.Id] = col
-			}
-		}
+		g.curBg = IndexedColor(uint8(seq.Id))
+	case SGRDefaultFg:
+		g.curFg = DefaultColor
+	case SGRDefaultBg:
+		g.curBg = DefaultColor
 	}
 }
 
-func (g *Window) parseSequences(str string, printExtra bool) int {
-	lastFound := 0
-	for i := 0; i < len(str); i++ {
-		if sgr, ok := extractSGR(str[i:]); ok {
-			i += len(sgr) - 1
+func (g *Window) handleEvent(event any) {
+	// Only printing and changing the attributes keep the grapheme cluster open.
+	switch e := event.(type) {
+	case PrintEvent:
+	case CSIEvent:
+		if !e.IsSGR() {
+			g.lastCell = nil
+		}
+	default:
+		g.lastCell = nil
+	}
 
-			if sgr, ok := parseSGR(sgr); ok {
-				lastFound = i
-				for i := range sgr {
-					g.handleSGR(sgr[i])
-					g.InvalidateBuffer()
+	switch e := event.(type) {
+	case PrintEvent:
+		g.printChar(e.Rune)
+	case ControlEvent:
+		g.handleControl(e.Code)
+	case ESCEvent:
+		if esc, ok := parseESC(e); ok {
+			g.handleCSI(esc)
+			g.InvalidateBuffer()
+		}
+	case OSCEvent:
+		g.handleOSC(e.Data)
+	case CSIEvent:
+		if e.IsSGR() {
+			var ok bool
+			if g.sgrBuffer, ok = parseSGRParams(e.Params, g.sgrBuffer[:0]); ok {
+				for i := range g.sgrBuffer {
+					g.handleSGR(g.sgrBuffer[i])
 				}
-			}
-		} else if csi, ok := extractCSI(str[i:]); ok {
-			i += len(csi) - 1
-
-			if csi, ok := parseCSI(csi); ok {
-				lastFound = i
-				g.handleCSI(csi)
 				g.InvalidateBuffer()
 			}
-		} else if printExtra {
-			if r, size := utf8.DecodeRuneInString(str[i:]); r != utf8.RuneError {
-				g.PrintChar(r, g.curFg, g.curBg, g.curWeight)
-				i += size - 1
-			}
+		} else if csi, ok := parseCSIEvent(e); ok {
+			g.handleCSI(csi)
+			g.InvalidateBuffer()
 		}
 	}
+}
 
-	return lastFound
+// feed parses the data and applies it to the terminal. Incomplete sequences at the
+// end of the data are kept by the parser until the next call.
+func (g *Window) feed(data []byte) {
+	g.parser.Feed(data, g.handleEvent)
 }
 
 func (g *Window) drainSequence() {
 	if len(g.seqBuffer) > 0 {
-		g.parseSequences(string(g.seqBuffer), true)
+		g.feed(g.seqBuffer)
 		g.seqBuffer = g.seqBuffer[:0]
 	}
 }
@@ -402,56 +624,81 @@ func (g *Window) drainSequence() {
 func (g *Window) RecalculateBackgrounds() {
 	for i := 0; i < g.cellsWidth; i++ {
 		for j := 0; j < g.cellsHeight; j++ {
-			g.SetBgPixels(i, j, g.grid[j][i].Bg)
+			g.syncBgPixels(i, j)
 		}
 	}
 }
 
-// PrintChar prints a character to the screen.
+// PrintChar prints a character to the screen with the given colors and font weight.
+// The current SGR attributes (e.g. underline) are applied to the cell.
 func (g *Window) PrintChar(r rune, fg, bg color.Color, weight FontWeight) {
-	if r == '\n' {
-		g.cursorX = 0
-		g.cursorY++
+	curFg, curBg, curWeight := g.curFg, g.curBg, g.curWeight
+	g.curFg, g.curBg, g.curWeight = ToCellColor(fg), ToCellColor(bg), weight
+	g.printChar(r)
+	g.curFg, g.curBg, g.curWeight = curFg, curBg, curWeight
+}
+
+// printChar prints a character to the screen with the current SGR attributes.
+func (g *Window) printChar(r rune) {
+	if r < 0x20 {
+		g.lastCell = nil
+		g.handleControl(byte(r))
 		return
 	}
 
-	if ansi.PrintableRuneWidth(string(r)) == 0 {
+	if g.extendGrapheme(r) {
 		return
 	}
 
-	// Wrap around if we're at the end of the line.
-	if g.cursorX >= g.cellsWidth {
+	width := g.runeWidth(r)
+	if width == 0 {
+		return
+	}
+	if width > g.cellsWidth {
+		width = g.cellsWidth
+	}
+
+	// Without autowrap the character overwrites the end of the line.
+	if g.cursorX+width > g.cellsWidth && !g.autowrap {
+		g.cursorX = g.cellsWidth - width
+	}
+
+	// Wrap around if the character doesn't fit on the line anymore.
+	if g.cursorX+width > g.cellsWidth {
+		if g.cursorX < g.cellsWidth && g.grid[g.cursorY][g.cursorX].Char == ' ' {
+			// A wide character leaves the last cell empty.
+			g.grid[g.cursorY][g.cursorX].Char = wideSpacer
+		}
+		g.grid[g.cursorY][g.cellsWidth-1].Attributes |= attrWrapline
 		g.cursorX = 0
-		g.cursorY++
-	}
-
-	// Scroll down if we're at the bottom and add a new line.
-	if g.cursorY >= g.cellsHeight {
-		diff := g.cursorY - g.cellsHeight + 1
-		g.grid = g.grid[diff:]
-		for i := 0; i < diff; i++ {
-			g.grid = append(g.grid, make([]GridCell, g.cellsWidth))
-			for i := 0; i < g.cellsWidth; i++ {
-				g.grid[len(g.grid)-1][i].Char = ' '
-				g.grid[len(g.grid)-1][i].Fg = color.White
-				g.grid[len(g.grid)-1][i].Bg = g.defaultBg
-			}
+		g.lineFeed()
+	}
+
+	// Set the cells. The right half of a wide character is a spacer with the same colors.
+	for i := 0; i < width; i++ {
+		cell := &g.grid[g.cursorY][g.cursorX+i]
+		cell.Char = r
+		if i > 0 {
+			cell.Char = wideSpacer
 		}
-		g.cursorY = g.cellsHeight - 1
-		g.RecalculateBackgrounds()
+		cell.Fg = g.curFg
+		cell.Bg = g.curBg
+		cell.Weight = g.curWeight
+		cell.Attributes = g.curAttrs | cell.Attributes&attrWrapline
+		cell.Link = g.curLink
+
+		// Set the pixels.
+		g.syncBgPixels(g.cursorX+i, g.cursorY)
 	}
 
-	// Set the cell.
-	g.grid[g.cursorY][g.cursorX].Char = r
-	g.grid[g.cursorY][g.cursorX].Fg = fg
-	g.grid[g.cursorY][g.cursorX].Bg = bg
-	g.grid[g.cursorY][g.cursorX].Weight = weight
+	g.lastCell = &g.grid[g.cursorY][g.cursorX]
 
-	// Set the pixels.
-	g.SetBgPixels(g.cursorX, g.cursorY, g.grid[g.cursorY][g.cursorX].Bg)
+	// Characters that were partly overwritten are removed.
+	g.fixWideCell(g.cursorY, g.cursorX-1)
+	g.fixWideCell(g.cursorY, g.cursorX+width)
 
 	// Move the cursor.
-	g.cursorX++
+	g.cursorX += width
 
 	g.InvalidateBuffer()
 }
@@ -487,59 +734,149 @@ func (g *Window) Update() error {
 		g.mouseCellX = mcx
 		g.mouseCellY = mcy
 
-		g.inputAdapter.HandleMouseMotion(MouseMotion{
-			X: g.mouseCellX,
-			Y: g.mouseCellY,
-		})
+		// Motion with a button held is reported as drag of that button.
+		button, drag := pressedMouseButton()
+		if g.reportsMouseMotion(drag) {
+			g.inputAdapter.HandleMouseMotion(MouseMotion{
+				X:      g.mouseCellX,
+				Y:      g.mouseCellY,
+				Button: button,
+				Drag:   drag,
+				Shift:  ebiten.IsKeyPressed(ebiten.KeyShift),
+				Alt:    ebiten.IsKeyPressed(ebiten.KeyAlt),
+				Ctrl:   ebiten.IsKeyPressed(ebiten.KeyControl),
+			})
+		}
 	}
+	g.updateHoverLink()
 
 	// Mouse buttons.
-	if inpututil.IsMouseButtonJustReleased(ebiten.MouseButtonLeft) {
-		g.inputAdapter.HandleMouseButton(MouseButton{
-			X:            g.mouseCellX,
-			Y:            g.mouseCellY,
-			Shift:        ebiten.IsKeyPressed(ebiten.KeyShift),
-			Alt:          ebiten.IsKeyPressed(ebiten.KeyAlt),
-			Ctrl:         ebiten.IsKeyPressed(ebiten.KeyControl),
-			Button:       ebiten.MouseButtonLeft,
-			JustPressed:  false,
-			JustReleased: true,
-		})
-	} else if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
-		g.inputAdapter.HandleMouseButton(MouseButton{
-			X:            g.mouseCellX,
-			Y:            g.mouseCellY,
-			Shift:        ebiten.IsKeyPressed(ebiten.KeyShift),
-			Alt:          ebiten.IsKeyPressed(ebiten.KeyAlt),
-			Ctrl:         ebiten.IsKeyPressed(ebiten.KeyControl),
-			Button:       ebiten.MouseButtonLeft,
-			JustPressed:  true,
-			JustReleased: false,
-		})
+	for button := ebiten.MouseButton0; button <= ebiten.MouseButtonMax; button++ {
+		if inpututil.IsMouseButtonJustPressed(button) {
+			g.handleMouseButton(button, true)
+		}
+		if inpututil.IsMouseButtonJustReleased(button) {
+			g.handleMouseButton(button, false)
+		}
 	}
 
-	// Mouse wheel.
-	_, wy := ebiten.Wheel()
-	if wy > 0 || wy < 0 {
+	// Mouse wheel. Without mouse reporting the wheel scrolls through the scrollback.
+	wx, wy := ebiten.Wheel()
+	if (wx != 0 || wy != 0) && g.reportsMouseWheel() {
 		g.inputAdapter.HandleMouseWheel(MouseWheel{
 			X:     g.mouseCellX,
 			Y:     g.mouseCellY,
 			Shift: ebiten.IsKeyPressed(ebiten.KeyShift),
 			Alt:   ebiten.IsKeyPressed(ebiten.KeyAlt),
 			Ctrl:  ebiten.IsKeyPressed(ebiten.KeyControl),
-			DX:    0,
+			DX:    wx,
 			DY:    wy,
 		})
+	} else if wy != 0 && !g.altScreen {
+		g.wheelDelta += wy * wheelLines
+		lines := int(g.wheelDelta)
+		g.wheelDelta -= float64(lines)
+		g.ScrollView(lines)
 	}
 
-	// Keyboard.
-	g.inputAdapter.HandleKeyPress()
+	// Keyboard. Shift+PageUp and Shift+PageDown scroll through the scrollback and are not passed on.
+	scrollKeys := ebiten.IsKeyPressed(ebiten.KeyShift) && !g.altScreen
+	if !g.handleZoomKeys() {
+		// Typing jumps back to the live screen.
+		if len(ebiten.AppendInputChars(nil)) > 0 || inpututil.IsKeyJustPressed(ebiten.KeyEnter) {
+			g.ScrollViewToBottom()
+		}
+
+		switch {
+		case scrollKeys && inpututil.IsKeyJustPressed(ebiten.KeyPageUp):
+			g.ScrollView(g.cellsHeight)
+		case scrollKeys && inpututil.IsKeyJustPressed(ebiten.KeyPageDown):
+			g.ScrollView(-g.cellsHeight)
+		}
+
+		g.handleKeyPress(func(key ebiten.Key) bool {
+			return scrollKeys && (key == ebiten.KeyPageUp || key == ebiten.KeyPageDown)
+		})
+	}
 
 	g.onUpdate()
 
 	return nil
 }
 
+// handleKeyPress passes the pressed keys to the input adapter, except the keys the window uses itself.
+// Adapters that can't leave out single keys get no keys while one of these keys is pressed.
+func (g *Window) handleKeyPress(used func(key ebiten.Key) bool) {
+	if adapter, ok := g.inputAdapter.(KeyFilterAdapter); ok {
+		adapter.HandleKeyPressExcept(used)
+		return
+	}
+
+	for _, key := range inpututil.AppendPressedKeys(nil) {
+		if used(key) {
+			return
+		}
+	}
+	g.inputAdapter.HandleKeyPress()
+}
+
+// drawCell draws the text and the decorations of a cell.
+func (g *Window) drawCell(bufferImage *ebiten.Image, x, y int) {
+	cell, ok := g.viewCell(x, y)
+	if !ok {
+		return
+	}
+
+	if cell.Attributes&AttrBlink != 0 {
+		g.hasBlink = true
+		if !g.blinkVisible {
+			return
+		}
+	}
+
+	if cell.Attributes&AttrConceal != 0 {
+		return
+	}
+
+	fg, _ := g.cellColors(cell)
+	px, py := x*g.cellWidth, y*g.cellHeight
+
+	if cell.Char != ' ' && cell.Char != wideSpacer {
+		switch cell.Weight {
+		case FontWeightNormal:
+			text.Draw(bufferImage, g.cellText(cell), g.fonts.Normal, px, py+g.cellOffsetY, fg)
+		case FontWeightBold:
+			text.Draw(bufferImage, g.cellText(cell), g.fonts.Bold, px, py+g.cellOffsetY, fg)
+		case FontWeightItalic:
+			text.Draw(bufferImage, g.cellText(cell), g.fonts.Italic, px, py+g.cellOffsetY, fg)
+		}
+	}
+
+	underline := cell.Attributes&AttrUnderline != 0 || (cell.Link != 0 && cell.Link == g.hoverLink)
+	if !underline && cell.Attributes&(AttrStrikethrough|AttrOverline) == 0 {
+		return
+	}
+
+	thickness := g.cellHeight / 16
+	if thickness < 1 {
+		thickness = 1
+	}
+
+	if underline {
+		underlineY := py + g.cellOffsetY + thickness
+		if underlineY > py+g.cellHeight-thickness {
+			underlineY = py + g.cellHeight - thickness
+		}
+		vector.DrawFilledRect(bufferImage, float32(px), float32(underlineY), float32(g.cellWidth), float32(thickness), fg, false)
+	}
+	if cell.Attributes&AttrStrikethrough != 0 {
+		vector.DrawFilledRect(bufferImage, float32(px), float32(py+g.cellHeight/2), float32(g.cellWidth), float32(thickness), fg, false)
+	}
+	if cell.Attributes&AttrOverline != 0 {
+		vector.DrawFilledRect(bufferImage, float32(px), float32(py), float32(g.cellWidth), float32(thickness), fg, false)
+	}
+}
+
 func (g *Window) Draw(screen *ebiten.Image) {
 	g.Lock()
 	defer g.Unlock()
@@ -554,34 +891,34 @@ func (g *Window) Draw(screen *ebiten.Image) {
 	// Get current buffer
 	bufferImage := g.lastBuffer
 
+	// Redraw if blinking text changes its visibility
+	if g.hasBlink {
+		if visible := time.Now().UnixMilli()/blinkInterval%2 == 0; visible != g.blinkVisible {
+			g.blinkVisible = visible
+			g.InvalidateBuffer()
+		}
+	}
+
 	// Only draw the buffer if it's invalid
 	if g.invalidateBuffer {
 		// Draw background
-		bufferImage.WritePixels(g.bgColors.Pix)
+		bufferImage.WritePixels(g.viewBackground().Pix)
 
 		// Draw text
+		g.hasBlink = false
 		for y := 0; y < g.cellsHeight; y++ {
 			for x := 0; x < g.cellsWidth; x++ {
-				if g.grid[y][x].Char == ' ' {
-					continue
-				}
-
-				switch g.grid[y][x].Weight {
-				case FontWeightNormal:
-					text.Draw(bufferImage, string(g.grid[y][x].Char), g.fonts.Normal, x*g.cellWidth, y*g.cellHeight+g.cellOffsetY, g.grid[y][x].Fg)
-				case FontWeightBold:
-					text.Draw(bufferImage, string(g.grid[y][x].Char), g.fonts.Bold, x*g.cellWidth, y*g.cellHeight+g.cellOffsetY, g.grid[y][x].Fg)
-				case FontWeightItalic:
-					text.Draw(bufferImage, string(g.grid[y][x].Char), g.fonts.Italic, x*g.cellWidth, y*g.cellHeight+g.cellOffsetY, g.grid[y][x].Fg)
-				}
+				g.drawCell(bufferImage, x, y)
 			}
 		}
 
 		// Draw cursor
-		if g.showCursor {
-			text.Draw(bufferImage, g.cursorChar, g.fonts.Normal, g.cursorX*g.cellWidth, g.cursorY*g.cellHeight+g.cellOffsetY, g.cursorColor)
+		if cursorY := g.cursorY + g.viewOffset; g.showCursor && cursorY < g.cellsHeight {
+			text.Draw(bufferImage, g.cursorChar, g.fonts.Normal, g.cursorX*g.cellWidth, cursorY*g.cellHeight+g.cellOffsetY, g.cursorColor)
 		}
 
+		g.drawScrollIndicator(bufferImage)
+
 		g.lastBuffer = bufferImage
 		g.invalidateBuffer = false
 	}
@@ -610,6 +947,8 @@ func (g *Window) Draw(screen *ebiten.Image) {
 		screen.DrawImage(bufferImage, nil)
 	}
 
+	g.drawVisualBell(screen)
+
 	if g.showTps {
 		ebitenutil.DebugPrint(screen, fmt.Sprintf("TPS: %0.2f", ebiten.CurrentTPS()))
 	}
@@ -619,13 +958,20 @@ func (g *Window) Draw(screen *ebiten.Image) {
 
 func (g *Window) Layout(outsideWidth, outsideHeight int) (int, int) {
 	s := DeviceScale()
-	return int(float64(outsideWidth) * s), int(float64(outsideHeight) * s)
+	width, height := int(float64(outsideWidth)*s), int(float64(outsideHeight)*s)
+
+	// Follow the size of the window if it can be resized.
+	if ebiten.WindowResizingMode() != ebiten.WindowResizingModeDisabled || ebiten.IsFullscreen() {
+		g.resizeToFit(width, height)
+	}
+
+	return width, height
 }
 
 func (g *Window) Run(title string) error {
 	ebiten.SetScreenFilterEnabled(false)
 	ebiten.SetWindowSize(int(float64(g.cellsWidth*g.cellWidth)/DeviceScale()), int(float64(g.cellsHeight*g.cellHeight)/DeviceScale()))
-	ebiten.SetWindowTitle(title)
+	g.SetTitle(title)
 	if err := ebiten.RunGame(g); err != nil {
 		return err
 	}
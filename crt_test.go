@@ -0,0 +1,310 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/basicfont"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+// newTestWindow creates a window with the given amount of cells that is backed by a basic font.
+func newTestWindow(t testing.TB, width, height int) *Window {
+	t.Setenv("CRT_DEVICE_SCALE", "1")
+
+	face := basicfont.Face7x13
+	bounds, _, _ := face.GlyphBounds('█')
+	size := bounds.Max.Sub(bounds.Min)
+
+	win, err := NewGame(width*size.X.Ceil(), height*size.Y.Ceil(), Fonts{Normal: face, Bold: face, Italic: face}, nil, NewEmptyAdapter(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, width, win.GetCellsWidth())
+	assert.Equal(t, height, win.GetCellsHeight())
+
+	return win
+}
+
+// screenLines returns the characters of the grid line by line with trailing spaces removed.
+// The right halves of wide characters have no text.
+func screenLines(win *Window) []string {
+	lines := make([]string, len(win.grid))
+	for y := range win.grid {
+		var sb strings.Builder
+		for x := range win.grid[y] {
+			sb.WriteString(win.cellText(win.grid[y][x]))
+		}
+		lines[y] = strings.TrimRight(sb.String(), " ")
+	}
+	return lines
+}
+
+func TestScrollingRegionLineFeed(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+
+	// Restrict scrolling to line 2-4 and feed two lines at the bottom margin.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ChangeScrollingRegionSeq, 2, 4)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 4, 1) + "A\nB\nC"))
+
+	assert.Equal(t, []string{"0", "A", "B", "C", "4"}, screenLines(win))
+	assert.Equal(t, 3, win.cursorY)
+}
+
+func TestScrollingRegionBelowMargin(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ChangeScrollingRegionSeq, 1, 3)))
+
+	// Line feeds below the region never scroll.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 5, 1) + "\n\nX"))
+
+	assert.Equal(t, []string{"0", "1", "2", "3", "X"}, screenLines(win))
+}
+
+func TestScrollingRegionWrap(t *testing.T) {
+	win := newTestWindow(t, 3, 4)
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ChangeScrollingRegionSeq, 1, 2)))
+	win.feed([]byte("abcdefghi"))
+
+	assert.Equal(t, []string{"def", "ghi", "", ""}, screenLines(win))
+}
+
+func TestScrollingRegionCursorHome(t *testing.T) {
+	win := newTestWindow(t, 10, 10)
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 5, 5)))
+
+	// DECSTBM homes the cursor.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ChangeScrollingRegionSeq, 3, 6)))
+	assert.Equal(t, 0, win.cursorX)
+	assert.Equal(t, 0, win.cursorY)
+
+	// With origin mode the home position is the top margin and positions are relative to it.
+	win.feed([]byte(termenv.CSI + "?6h"))
+	assert.Equal(t, 2, win.cursorY)
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 2, 3)))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 3, win.cursorY)
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 10, 1)))
+	assert.Equal(t, 5, win.cursorY)
+
+	// Resetting origin mode goes back to absolute positions.
+	win.feed([]byte(termenv.CSI + "?6l"))
+	assert.Equal(t, 0, win.cursorY)
+}
+
+func TestScrollingRegionInvalid(t *testing.T) {
+	win := newTestWindow(t, 10, 10)
+
+	win.SetScrollingRegion(5, 5)
+	top, bottom := win.GetScrollingRegion()
+	assert.Equal(t, 1, top)
+	assert.Equal(t, 10, bottom)
+
+	win.SetScrollingRegion(3, 50)
+	top, bottom = win.GetScrollingRegion()
+	assert.Equal(t, 3, top)
+	assert.Equal(t, 10, bottom)
+
+	win.feed([]byte(termenv.CSI + "r"))
+	top, bottom = win.GetScrollingRegion()
+	assert.Equal(t, 1, top)
+	assert.Equal(t, 10, bottom)
+}
+
+func TestScrollingRegionCursorMovement(t *testing.T) {
+	win := newTestWindow(t, 10, 10)
+	win.SetScrollingRegion(3, 6)
+
+	// Vertical movement inside the region stops at the margins.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 4, 1)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorUpSeq, 5)))
+	assert.Equal(t, 2, win.cursorY)
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorDownSeq, 10)))
+	assert.Equal(t, 5, win.cursorY)
+
+	// Outside the region the screen edges apply.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 8, 1)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorDownSeq, 10)))
+	assert.Equal(t, 9, win.cursorY)
+}
+
+func TestScrollUpDown(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollUpSeq, 2)))
+	assert.Equal(t, []string{"2", "3", "4", "", ""}, screenLines(win))
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollDownSeq, 1)))
+	assert.Equal(t, []string{"", "2", "3", "4", ""}, screenLines(win))
+
+	// Scrolling more lines than the region has clears it.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollUpSeq, 20)))
+	assert.Equal(t, []string{"", "", "", "", ""}, screenLines(win))
+}
+
+func TestScrollUpDownRegion(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+	win.SetScrollingRegion(2, 4)
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollUpSeq, 1)))
+	assert.Equal(t, []string{"0", "2", "3", "", "4"}, screenLines(win))
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollDownSeq, 2)))
+	assert.Equal(t, []string{"0", "", "", "2", "4"}, screenLines(win))
+}
+
+func TestInsertDeleteLine(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 2, 3)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.InsertLineSeq, 2)))
+	assert.Equal(t, []string{"0", "", "", "1", "2"}, screenLines(win))
+	assert.Equal(t, 0, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.DeleteLineSeq, 3)))
+	assert.Equal(t, []string{"0", "2", "", "", ""}, screenLines(win))
+}
+
+func TestInsertDeleteLineRegion(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+	win.SetScrollingRegion(2, 4)
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 3, 1)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.InsertLineSeq, 1)))
+	assert.Equal(t, []string{"0", "1", "", "2", "4"}, screenLines(win))
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.DeleteLineSeq, 1)))
+	assert.Equal(t, []string{"0", "1", "2", "", "4"}, screenLines(win))
+
+	// Outside of the region the sequences are ignored.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 5, 1)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.DeleteLineSeq, 1)))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.InsertLineSeq, 1)))
+	assert.Equal(t, []string{"0", "1", "2", "", "4"}, screenLines(win))
+}
+
+func TestScrollFillsCurrentBackground(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	win.feed([]byte(termenv.CSI + "48;2;255;10;10m"))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollUpSeq, 1)))
+	assert.Equal(t, RGBColor(255, 10, 10), win.curBg)
+
+	for x := 0; x < 10; x++ {
+		assert.Equal(t, win.curBg, win.grid[2][x].Bg)
+		assert.Equal(t, DefaultColor, win.grid[1][x].Bg)
+	}
+
+	// The background pixels follow the grid.
+	r, _, _, _ := win.bgColors.At(0, 2*win.cellHeight).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	r, _, _, _ = win.bgColors.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0), r)
+}
+
+func TestSaveRestoreCursor(t *testing.T) {
+	for _, seq := range []struct {
+		name    string
+		save    string
+		restore string
+	}{
+		{"ANSI", termenv.CSI + termenv.SaveCursorPositionSeq, termenv.CSI + termenv.RestoreCursorPositionSeq},
+		{"DEC", ESC + "7", ESC + "8"},
+	} {
+		t.Run(seq.name, func(t *testing.T) {
+			win := newTestWindow(t, 10, 5)
+
+			win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 2, 3) + termenv.CSI + "1;38;2;255;10;10m"))
+			win.feed([]byte(seq.save))
+			savedFg := win.curFg
+
+			win.feed([]byte(termenv.CSI + "0m" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 5, 5) + "X"))
+			assert.Equal(t, FontWeightNormal, win.curWeight)
+
+			win.feed([]byte(seq.restore + "Y"))
+			assert.Equal(t, "  Y", screenLines(win)[1])
+			assert.Equal(t, savedFg, win.grid[1][2].Fg)
+			assert.Equal(t, FontWeightBold, win.grid[1][2].Weight)
+			assert.Equal(t, 3, win.cursorX)
+			assert.Equal(t, 1, win.cursorY)
+		})
+	}
+}
+
+func TestSaveRestoreCursorOriginMode(t *testing.T) {
+	win := newTestWindow(t, 10, 10)
+	win.SetScrollingRegion(3, 6)
+	win.feed([]byte(termenv.CSI + "?6h"))
+	win.SaveCursor()
+
+	win.feed([]byte(termenv.CSI + "?6l"))
+	assert.False(t, win.originMode)
+
+	win.RestoreCursor()
+	assert.True(t, win.originMode)
+	assert.Equal(t, 2, win.cursorY)
+}
+
+func TestSaveRestoreCursorAutowrap(t *testing.T) {
+	win := newTestWindow(t, 10, 10)
+	win.feed([]byte(termenv.CSI + "?7l" + ESC + "7" + termenv.CSI + "?7h"))
+	assert.True(t, win.autowrap)
+
+	win.feed([]byte(ESC + "8"))
+	assert.False(t, win.autowrap)
+}
+
+func TestAutowrapOff(t *testing.T) {
+	win := newTestWindow(t, 5, 3)
+	win.feed([]byte(termenv.CSI + "?7l" + "abcdefg" + "\r\n" + "ab" + "界" + "界"))
+	// The second wide character replaces the end of the first one.
+	assert.Equal(t, []string{"abcdg", "ab 界", ""}, screenLines(win))
+	assert.Equal(t, 1, win.cursorY)
+
+	win.feed([]byte(termenv.CSI + "?7h" + "\r\n" + "abcdefg"))
+	assert.Equal(t, []string{"ab 界", "abcde", "fg"}, screenLines(win))
+}
+
+func TestRestoreCursorWithoutSave(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	win.feed([]byte(termenv.CSI + "1m" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 3, 3)))
+
+	win.RestoreCursor()
+	assert.Equal(t, 0, win.cursorX)
+	assert.Equal(t, 0, win.cursorY)
+	assert.Equal(t, FontWeightNormal, win.curWeight)
+}
+
+func TestSGRAttributesApplied(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.feed([]byte(termenv.CSI + "4;9mA" + termenv.CSI + "24mB" + termenv.CSI + "2;5;8;53mC" + termenv.CSI + "22mD" + termenv.CSI + "0mE"))
+
+	assert.Equal(t, AttrUnderline|AttrStrikethrough, win.grid[0][0].Attributes)
+	assert.Equal(t, AttrStrikethrough, win.grid[0][1].Attributes)
+	assert.Equal(t, AttrStrikethrough|AttrDim|AttrBlink|AttrConceal|AttrOverline, win.grid[0][2].Attributes)
+	assert.Equal(t, AttrStrikethrough|AttrBlink|AttrConceal|AttrOverline, win.grid[0][3].Attributes)
+	assert.Equal(t, CellAttributes(0), win.grid[0][4].Attributes)
+}
+
+func TestSGRReverseBackground(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.feed([]byte(termenv.CSI + "38;2;255;10;10;7mA" + termenv.CSI + "27mB"))
+
+	// Reversed cells are drawn with the foreground color as background.
+	r, _, _, _ := win.bgColors.At(0, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+	r, _, _, _ = win.bgColors.At(win.cellWidth, 0).RGBA()
+	assert.Equal(t, uint32(0), r)
+
+	fg, bg := win.cellColors(win.grid[0][0])
+	assert.Equal(t, win.defaultBg, fg)
+	assert.Equal(t, color.Color(color.RGBA{R: 255, G: 10, B: 10, A: 255}), bg)
+}
@@ -1,15 +1,11 @@
 package crt
 
 import (
-	"github.com/muesli/termenv"
+	"errors"
 	"strconv"
 	"strings"
-	"sync"
 )
 
-var csiMtx = &sync.Mutex{}
-var csiCache = map[string]any{}
-
 type CursorUpSeq struct {
 	Count int
 }
@@ -51,6 +47,18 @@ type EraseLineSeq struct {
 	Type int
 }
 
+type EraseCharacterSeq struct {
+	Count int
+}
+
+type InsertCharacterSeq struct {
+	Count int
+}
+
+type DeleteCharacterSeq struct {
+	Count int
+}
+
 type ScrollUpSeq struct {
 	Count int
 }
@@ -76,150 +84,290 @@ type DeleteLineSeq struct {
 	Count int
 }
 
-type CursorShowSeq struct{}
+type SetPrivateModeSeq struct {
+	Modes []int
+}
 
-type CursorHideSeq struct{}
+type ResetPrivateModeSeq struct {
+	Modes []int
+}
 
-// extractCSI extracts a CSI sequence from the beginning of a string.
-// It returns the sequence without any suffix, and a boolean indicating
-// whether a sequence was found.
-func extractCSI(s string) (string, bool) {
-	if !strings.HasPrefix(s, termenv.CSI) {
-		return "", false
-	}
+type SetModeSeq struct {
+	Modes []int
+}
 
-	s = s[len(termenv.CSI):]
-	if len(s) == 0 {
-		return "", false
-	}
+type ResetModeSeq struct {
+	Modes []int
+}
 
-	for i, c := range s {
-		if c >= '@' && c <= '~' {
-			return termenv.CSI + s[:i+1], true
-		}
-	}
+type TabClearSeq struct {
+	Type int
+}
 
-	return "", false
+type CursorForwardTabSeq struct {
+	Count int
 }
 
-// parseCSI parses a CSI sequence and returns a struct representing the sequence.
-func parseCSI(s string) (any, bool) {
-	if !strings.HasPrefix(s, termenv.CSI) {
-		return nil, false
-	}
+type CursorBackwardTabSeq struct {
+	Count int
+}
 
-	s = s[len(termenv.CSI):]
-	if len(s) == 0 {
-		return nil, false
-	}
+type DeviceStatusReportSeq struct {
+	Type    int
+	Private bool
+}
 
-	csiMtx.Lock()
-	if cached, ok := csiCache[s]; ok {
-		csiMtx.Unlock()
-		return cached, true
-	}
-	csiMtx.Unlock()
+type PrimaryDeviceAttributesSeq struct{}
 
-	if val, ok := parseCSIStruct(s); ok {
-		csiMtx.Lock()
-		csiCache[s] = val
-		csiMtx.Unlock()
+type SecondaryDeviceAttributesSeq struct{}
 
-		return val, true
-	}
+type TerminalVersionSeq struct{}
 
-	return nil, false
+type RequestModeSeq struct {
+	Mode    int
+	Private bool
 }
 
-func parseCSIStruct(s string) (any, bool) {
-	switch s {
-	case termenv.ShowCursorSeq:
-		return CursorShowSeq{}, true
-	case termenv.HideCursorSeq:
-		return CursorHideSeq{}, true
+type CursorShowSeq struct{}
+
+type CursorHideSeq struct{}
+
+// parseCSIEvent returns a struct representing the CSI sequence of the event.
+func parseCSIEvent(e CSIEvent) (any, bool) {
+	params := e.Params
+
+	// Private (e.g. ?25h) and intermediate (e.g. 1$p) forms of the sequences.
+	switch {
+	case e.Prefix == '?' && e.Intermediates == "":
+		switch e.Final {
+		case 'h':
+			if params == "25" {
+				return CursorShowSeq{}, true
+			}
+			if modes, ok := parseModes(params); ok {
+				return SetPrivateModeSeq{Modes: modes}, true
+			}
+		case 'l':
+			if params == "25" {
+				return CursorHideSeq{}, true
+			}
+			if modes, ok := parseModes(params); ok {
+				return ResetPrivateModeSeq{Modes: modes}, true
+			}
+		case 'n':
+			if t, ok := parseOptionalInt(params); ok {
+				return DeviceStatusReportSeq{Type: t, Private: true}, true
+			}
+		}
+		return nil, false
+	case e.Prefix == '>' && e.Intermediates == "":
+		if params != "" && params != "0" {
+			return nil, false
+		}
+		switch e.Final {
+		case 'c':
+			return SecondaryDeviceAttributesSeq{}, true
+		case 'q':
+			return TerminalVersionSeq{}, true
+		}
+		return nil, false
+	case e.Intermediates == "$" && e.Final == 'p' && (e.Prefix == 0 || e.Prefix == '?'):
+		if mode, ok := parseParam(params); ok {
+			return RequestModeSeq{Mode: mode, Private: e.Prefix == '?'}, true
+		}
+		return nil, false
+	case e.Prefix != 0 || e.Intermediates != "":
+		return nil, false
 	}
 
-	switch s[len(s)-1] {
+	switch e.Final {
 	case 'A':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorUpSeq{Count: count}, true
 		}
 	case 'B':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorDownSeq{Count: count}, true
 		}
 	case 'C':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorForwardSeq{Count: count}, true
 		}
 	case 'D':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorBackSeq{Count: count}, true
 		}
 	case 'E':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorNextLineSeq{Count: count}, true
 		}
 	case 'F':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorPreviousLineSeq{Count: count}, true
 		}
 	case 'G':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return CursorHorizontalSeq{Count: count}, true
 		}
-	case 'H':
-		if strings.Contains(s, ";") {
-			parts := strings.Split(s[:len(s)-1], ";")
-			if len(parts) != 2 {
-				return nil, false
-			}
-			row, err := strconv.Atoi(parts[0])
-			if err != nil {
-				return nil, false
-			}
-			col, err := strconv.Atoi(parts[1])
-			if err != nil {
-				return nil, false
-			}
-			return CursorPositionSeq{Row: row, Col: col}, true
+	case 'H', 'f':
+		rowStr, colStr, _ := strings.Cut(params, ";")
+		if strings.Contains(colStr, ";") {
+			return nil, false
 		}
-		return nil, false
+
+		row, ok := parseOptionalInt(rowStr)
+		if !ok {
+			return nil, false
+		}
+		col, ok := parseOptionalInt(colStr)
+		if !ok {
+			return nil, false
+		}
+		return CursorPositionSeq{Row: countOrOne(row), Col: countOrOne(col)}, true
 	case 'J':
-		if t, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if t, ok := parseOptionalInt(params); ok {
 			return EraseDisplaySeq{Type: t}, true
 		}
 	case 'K':
-		if t, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if t, ok := parseOptionalInt(params); ok {
 			return EraseLineSeq{Type: t}, true
 		}
+	case 'X':
+		if count, ok := parseOptionalInt(params); ok {
+			return EraseCharacterSeq{Count: count}, true
+		}
+	case '@':
+		if count, ok := parseOptionalInt(params); ok {
+			return InsertCharacterSeq{Count: count}, true
+		}
+	case 'P':
+		if count, ok := parseOptionalInt(params); ok {
+			return DeleteCharacterSeq{Count: count}, true
+		}
 	case 'S':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return ScrollUpSeq{Count: count}, true
 		}
 	case 'T':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return ScrollDownSeq{Count: count}, true
 		}
 	case 's':
-		if len(s) == 1 {
+		if params == "" {
 			return SaveCursorPositionSeq{}, true
 		}
 	case 'u':
-		if len(s) == 1 {
+		if params == "" {
 			return RestoreCursorPositionSeq{}, true
 		}
 	case 'r':
-		// TODO: implement
+		topStr, bottomStr, _ := strings.Cut(params, ";")
+		if strings.Contains(bottomStr, ";") {
+			return nil, false
+		}
+
+		top, ok := parseOptionalInt(topStr)
+		if !ok {
+			return nil, false
+		}
+		bottom, ok := parseOptionalInt(bottomStr)
+		if !ok {
+			return nil, false
+		}
+		return ChangeScrollingRegionSeq{Top: top, Bottom: bottom}, true
+	case 'h':
+		if modes, ok := parseModes(params); ok {
+			return SetModeSeq{Modes: modes}, true
+		}
+	case 'l':
+		if modes, ok := parseModes(params); ok {
+			return ResetModeSeq{Modes: modes}, true
+		}
+	case 'n':
+		if t, ok := parseOptionalInt(params); ok {
+			return DeviceStatusReportSeq{Type: t}, true
+		}
+	case 'c':
+		if params == "" || params == "0" {
+			return PrimaryDeviceAttributesSeq{}, true
+		}
+	case 'g':
+		if t, ok := parseOptionalInt(params); ok {
+			return TabClearSeq{Type: t}, true
+		}
+	case 'I':
+		if count, ok := parseOptionalInt(params); ok {
+			return CursorForwardTabSeq{Count: count}, true
+		}
+	case 'Z':
+		if count, ok := parseOptionalInt(params); ok {
+			return CursorBackwardTabSeq{Count: count}, true
+		}
 	case 'L':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return InsertLineSeq{Count: count}, true
 		}
 	case 'M':
-		if count, err := strconv.Atoi(s[:len(s)-1]); err == nil {
+		if count, ok := parseOptionalInt(params); ok {
 			return DeleteLineSeq{Count: count}, true
 		}
 	}
 
 	return nil, false
 }
+
+// parseModes parses the mode list of a mode sequence (e.g. "6;1049").
+func parseModes(s string) ([]int, bool) {
+	if s == "" {
+		return nil, false
+	}
+
+	modes := make([]int, strings.Count(s, ";")+1)
+	for i := range modes {
+		var part string
+		part, s, _ = strings.Cut(s, ";")
+		mode, ok := parseParam(part)
+		if !ok {
+			return nil, false
+		}
+		modes[i] = mode
+	}
+
+	return modes, true
+}
+
+// parseOptionalInt parses a single numeric parameter. An omitted parameter is returned as zero.
+func parseOptionalInt(s string) (int, bool) {
+	if s == "" {
+		return 0, true
+	}
+
+	return parseParam(s)
+}
+
+// maxParam is the largest value of a numeric parameter. Like in xterm larger values are clamped,
+// so counts can't overflow the cursor position.
+const maxParam = 65535
+
+// parseParam parses a numeric parameter and clamps it to maxParam.
+func parseParam(s string) (int, bool) {
+	val, err := strconv.Atoi(s)
+	if errors.Is(err, strconv.ErrRange) && val > 0 {
+		return maxParam, true
+	}
+	if err != nil {
+		return 0, false
+	}
+	if val > maxParam {
+		val = maxParam
+	}
+	return val, true
+}
+
+// countOrOne returns the count of a sequence or 1 if the count is zero, which is the default for most sequences.
+func countOrOne(count int) int {
+	if count < 1 {
+		return 1
+	}
+	return count
+}
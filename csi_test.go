@@ -20,16 +20,13 @@ func TestCSI(t *testing.T) {
 	testString += fmt.Sprintf(termenv.CSI+termenv.CursorBackSeq, 5)
 
 	var sequences []any
-	for i := 0; i < len(testString); i++ {
-		csi, ok := extractCSI(testString[i:])
-		if ok {
-			i += len(csi) - 1
-
-			if res, ok := parseCSI(csi); ok {
+	NewParser().Feed([]byte(testString), func(event any) {
+		if csi, ok := event.(CSIEvent); ok {
+			if res, ok := parseCSIEvent(csi); ok {
 				sequences = append(sequences, res)
 			}
 		}
-	}
+	})
 
 	assert.Equal(t, []any{
 		EraseDisplaySeq{Type: 20},
@@ -40,3 +37,128 @@ func TestCSI(t *testing.T) {
 		CursorBackSeq{Count: 5},
 	}, sequences)
 }
+
+func TestCSIScrollingRegion(t *testing.T) {
+	tests := []struct {
+		event CSIEvent
+		want  any
+	}{
+		{CSIEvent{Final: 'r'}, ChangeScrollingRegionSeq{}},
+		{CSIEvent{Params: "5", Final: 'r'}, ChangeScrollingRegionSeq{Top: 5}},
+		{CSIEvent{Params: ";10", Final: 'r'}, ChangeScrollingRegionSeq{Bottom: 10}},
+		{CSIEvent{Params: "2;20", Final: 'r'}, ChangeScrollingRegionSeq{Top: 2, Bottom: 20}},
+		{CSIEvent{Prefix: '?', Params: "6", Final: 'h'}, SetPrivateModeSeq{Modes: []int{6}}},
+		{CSIEvent{Prefix: '?', Params: "6;7", Final: 'l'}, ResetPrivateModeSeq{Modes: []int{6, 7}}},
+	}
+
+	for _, test := range tests {
+		res, ok := parseCSIEvent(test.event)
+		assert.True(t, ok, test.event.String())
+		assert.Equal(t, test.want, res, test.event.String())
+	}
+
+	_, ok := parseCSIEvent(CSIEvent{Params: "1;2;3", Final: 'r'})
+	assert.False(t, ok)
+}
+
+func TestCSIEditing(t *testing.T) {
+	tests := []struct {
+		event CSIEvent
+		want  any
+	}{
+		{CSIEvent{Final: 'J'}, EraseDisplaySeq{Type: 0}},
+		{CSIEvent{Params: "3", Final: 'J'}, EraseDisplaySeq{Type: 3}},
+		{CSIEvent{Final: 'K'}, EraseLineSeq{Type: 0}},
+		{CSIEvent{Params: "1", Final: 'K'}, EraseLineSeq{Type: 1}},
+		{CSIEvent{Params: "4", Final: 'X'}, EraseCharacterSeq{Count: 4}},
+		{CSIEvent{Final: '@'}, InsertCharacterSeq{Count: 0}},
+		{CSIEvent{Params: "2", Final: '@'}, InsertCharacterSeq{Count: 2}},
+		{CSIEvent{Params: "3", Final: 'P'}, DeleteCharacterSeq{Count: 3}},
+	}
+
+	for _, test := range tests {
+		res, ok := parseCSIEvent(test.event)
+		assert.True(t, ok, test.event.String())
+		assert.Equal(t, test.want, res, test.event.String())
+	}
+}
+
+func TestCSIModesAndTabs(t *testing.T) {
+	tests := []struct {
+		event CSIEvent
+		want  any
+	}{
+		{CSIEvent{Params: "20", Final: 'h'}, SetModeSeq{Modes: []int{20}}},
+		{CSIEvent{Params: "4;20", Final: 'l'}, ResetModeSeq{Modes: []int{4, 20}}},
+		{CSIEvent{Prefix: '?', Params: "25", Final: 'h'}, CursorShowSeq{}},
+		{CSIEvent{Prefix: '?', Params: "6", Final: 'h'}, SetPrivateModeSeq{Modes: []int{6}}},
+		{CSIEvent{Final: 'g'}, TabClearSeq{Type: 0}},
+		{CSIEvent{Params: "3", Final: 'g'}, TabClearSeq{Type: 3}},
+		{CSIEvent{Final: 'I'}, CursorForwardTabSeq{Count: 0}},
+		{CSIEvent{Params: "2", Final: 'Z'}, CursorBackwardTabSeq{Count: 2}},
+	}
+
+	for _, test := range tests {
+		res, ok := parseCSIEvent(test.event)
+		assert.True(t, ok, test.event.String())
+		assert.Equal(t, test.want, res, test.event.String())
+	}
+
+	_, ok := parseCSIEvent(CSIEvent{Final: 'h'})
+	assert.False(t, ok)
+}
+
+func TestCSIQueries(t *testing.T) {
+	tests := []struct {
+		event CSIEvent
+		want  any
+	}{
+		{CSIEvent{Params: "5", Final: 'n'}, DeviceStatusReportSeq{Type: 5}},
+		{CSIEvent{Params: "6", Final: 'n'}, DeviceStatusReportSeq{Type: 6}},
+		{CSIEvent{Prefix: '?', Params: "6", Final: 'n'}, DeviceStatusReportSeq{Type: 6, Private: true}},
+		{CSIEvent{Final: 'c'}, PrimaryDeviceAttributesSeq{}},
+		{CSIEvent{Params: "0", Final: 'c'}, PrimaryDeviceAttributesSeq{}},
+		{CSIEvent{Prefix: '>', Final: 'c'}, SecondaryDeviceAttributesSeq{}},
+		{CSIEvent{Prefix: '>', Final: 'q'}, TerminalVersionSeq{}},
+		{CSIEvent{Params: "20", Intermediates: "$", Final: 'p'}, RequestModeSeq{Mode: 20}},
+		{CSIEvent{Prefix: '?', Params: "1049", Intermediates: "$", Final: 'p'}, RequestModeSeq{Mode: 1049, Private: true}},
+	}
+
+	for _, test := range tests {
+		res, ok := parseCSIEvent(test.event)
+		assert.True(t, ok, test.event.String())
+		assert.Equal(t, test.want, res, test.event.String())
+	}
+
+	for _, event := range []CSIEvent{
+		{Prefix: '?', Final: 'u'},
+		{Params: "1", Intermediates: " ", Final: 'q'},
+		{Intermediates: "!", Final: 'p'},
+		{Intermediates: "$", Final: 'p'},
+	} {
+		_, ok := parseCSIEvent(event)
+		assert.False(t, ok, event.String())
+	}
+}
+
+func TestCSIHugeCounts(t *testing.T) {
+	res, ok := parseCSIEvent(CSIEvent{Params: "99999999999999999999999", Final: 'C'})
+	assert.True(t, ok)
+	assert.Equal(t, CursorForwardSeq{Count: maxParam}, res)
+
+	for _, count := range []string{"65536", "9223372036854775807", "99999999999999999999999"} {
+		for _, final := range "ABCDEFGd@PXLMST" {
+			win := newTestWindow(t, 10, 3)
+			win.feed([]byte("abc\r\ndef" + termenv.CSI + count + string(final) + "x"))
+			assert.GreaterOrEqual(t, win.cursorX, 0, string(final))
+			assert.LessOrEqual(t, win.cursorX, win.cellsWidth, string(final))
+			assert.GreaterOrEqual(t, win.cursorY, 0, string(final))
+			assert.Less(t, win.cursorY, win.cellsHeight, string(final))
+		}
+
+		win := newTestWindow(t, 10, 3)
+		win.feed([]byte(termenv.CSI + count + ";" + count + "H"))
+		assert.Equal(t, 9, win.cursorX)
+		assert.Equal(t, 2, win.cursorY)
+	}
+}
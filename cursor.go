@@ -0,0 +1,55 @@
+package crt
+
+// savedCursor is the cursor state that is stored by SaveCursor and restored by RestoreCursor.
+type savedCursor struct {
+	x          int
+	y          int
+	fg         CellColor
+	bg         CellColor
+	weight     FontWeight
+	attrs      CellAttributes
+	originMode bool
+	autowrap   bool
+}
+
+// SaveCursor saves the cursor position, the SGR attributes, the origin mode and autowrap like DECSC.
+func (g *Window) SaveCursor() {
+	g.savedCursor = &savedCursor{
+		x:          g.cursorX,
+		y:          g.cursorY,
+		fg:         g.curFg,
+		bg:         g.curBg,
+		weight:     g.curWeight,
+		attrs:      g.curAttrs,
+		originMode: g.originMode,
+		autowrap:   g.autowrap,
+	}
+}
+
+// RestoreCursor restores the state saved by SaveCursor like DECRC. If nothing was saved
+// the cursor is moved to the home position and the attributes are reset.
+func (g *Window) RestoreCursor() {
+	if g.savedCursor == nil {
+		g.originMode = false
+		g.ResetSGR()
+		g.cursorHome()
+		return
+	}
+
+	g.cursorX = g.savedCursor.x
+	g.cursorY = g.savedCursor.y
+	g.curFg = g.savedCursor.fg
+	g.curBg = g.savedCursor.bg
+	g.curWeight = g.savedCursor.weight
+	g.curAttrs = g.savedCursor.attrs
+	g.originMode = g.savedCursor.originMode
+	g.autowrap = g.savedCursor.autowrap
+
+	// The screen might have changed since the cursor was saved.
+	if g.cursorX > g.cellsWidth {
+		g.cursorX = g.cellsWidth
+	}
+	if g.cursorY >= g.cellsHeight {
+		g.cursorY = g.cellsHeight - 1
+	}
+}
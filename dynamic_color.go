@@ -0,0 +1,190 @@
+package crt
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// Indices of the dynamic colors that follow the 256 color table.
+const (
+	dynamicFg = 256 + iota
+	dynamicBg
+	dynamicCursor
+)
+
+// parseColorSpec parses a color in the X11 forms rgb:r/g/b with 1 to 4 hex digits
+// per component and #rgb, #rrggbb, #rrrgggbbb or #rrrrggggbbbb.
+func parseColorSpec(s string) (color.Color, bool) {
+	var parts []string
+	switch {
+	case strings.HasPrefix(s, "rgb:"):
+		parts = strings.Split(s[4:], "/")
+		if len(parts) != 3 {
+			return nil, false
+		}
+	case strings.HasPrefix(s, "#"):
+		hex := s[1:]
+		n := len(hex) / 3
+		if n < 1 || n > 4 || len(hex)%3 != 0 {
+			return nil, false
+		}
+		parts = []string{hex[:n], hex[n : 2*n], hex[2*n:]}
+	default:
+		return nil, false
+	}
+
+	var rgb [3]uint8
+	for i, part := range parts {
+		if len(part) < 1 || len(part) > 4 {
+			return nil, false
+		}
+		val, err := strconv.ParseUint(part, 16, 16)
+		if err != nil {
+			return nil, false
+		}
+
+		// Scale the component to 8 bit.
+		max := uint64(1)<<(4*len(part)) - 1
+		rgb[i] = uint8((val*0xff + max/2) / max)
+	}
+
+	return color.RGBA{R: rgb[0], G: rgb[1], B: rgb[2], A: 0xff}, true
+}
+
+// formatColorSpec formats a color as rgb:rrrr/gggg/bbbb like the replies of xterm.
+func formatColorSpec(c color.Color) string {
+	n := color.NRGBAModel.Convert(c).(color.NRGBA)
+	return fmt.Sprintf("rgb:%04x/%04x/%04x", uint16(n.R)*0x101, uint16(n.G)*0x101, uint16(n.B)*0x101)
+}
+
+// getDynamicColor returns an entry of the 256 color table or one of the dynamic colors.
+func (g *Window) getDynamicColor(index int) (color.Color, bool) {
+	switch index {
+	case dynamicFg:
+		return g.defaultFg, true
+	case dynamicBg:
+		return g.defaultBg, true
+	case dynamicCursor:
+		return g.cursorColor, true
+	}
+	return g.ansiColor(index)
+}
+
+// setDynamicColor changes an entry of the 256 color table or one of the dynamic colors. The first
+// change keeps the previous color, so it can be restored by resetDynamicColor.
+func (g *Window) setDynamicColor(index int, c color.Color) {
+	if index < 0 || index > dynamicCursor {
+		return
+	}
+
+	if _, ok := g.savedColors[index]; !ok {
+		if prev, ok := g.getDynamicColor(index); ok {
+			if g.savedColors == nil {
+				g.savedColors = map[int]color.Color{}
+			}
+			g.savedColors[index] = prev
+		}
+	}
+
+	g.applyDynamicColor(index, c)
+}
+
+// resetDynamicColor restores a color that was changed by setDynamicColor.
+func (g *Window) resetDynamicColor(index int) {
+	prev, ok := g.savedColors[index]
+	if !ok {
+		return
+	}
+	delete(g.savedColors, index)
+
+	// Colors above the palette fall back to the color table.
+	if index >= len(g.palette) && index < dynamicFg {
+		delete(g.colorOverrides, index)
+		g.RecalculateBackgrounds()
+		g.InvalidateBuffer()
+		return
+	}
+	g.applyDynamicColor(index, prev)
+}
+
+// applyDynamicColor sets a color and redraws the screen.
+func (g *Window) applyDynamicColor(index int, c color.Color) {
+	switch {
+	case index == dynamicFg:
+		g.defaultFg = c
+	case index == dynamicBg:
+		g.defaultBg = c
+	case index == dynamicCursor:
+		g.cursorColor = c
+	case index < len(g.palette):
+		g.palette[index] = c
+	default:
+		if g.colorOverrides == nil {
+			g.colorOverrides = map[int]color.Color{}
+		}
+		g.colorOverrides[index] = c
+	}
+
+	g.RecalculateBackgrounds()
+	g.InvalidateBuffer()
+}
+
+// handleColor handles OSC 4 (index;spec pairs), which sets or queries entries of the 256 color table.
+func (g *Window) handleColor(data string) {
+	args := strings.Split(data, ";")
+	for i := 0; i+1 < len(args); i += 2 {
+		index, err := strconv.Atoi(args[i])
+		if err != nil || index < 0 || index > 255 {
+			continue
+		}
+
+		if args[i+1] == "?" {
+			if c, ok := g.getDynamicColor(index); ok {
+				g.respond(ESC + "]4;" + args[i] + ";" + formatColorSpec(c) + ESC + "\\")
+			}
+		} else if c, ok := parseColorSpec(args[i+1]); ok {
+			g.setDynamicColor(index, c)
+		}
+	}
+}
+
+// handleDynamicColors returns the handler of OSC 10, 11 or 12, which set or query the foreground,
+// background or cursor color. Like in xterm additional specs apply to the following colors.
+func (g *Window) handleDynamicColors(code int) OSCHandler {
+	return func(data string) {
+		for i, spec := range strings.Split(data, ";") {
+			index := dynamicFg + code - 10 + i
+			if index > dynamicCursor {
+				return
+			}
+
+			if spec == "?" {
+				if c, ok := g.getDynamicColor(index); ok {
+					g.respond(fmt.Sprintf("%s]%d;%s%s\\", ESC, code+i, formatColorSpec(c), ESC))
+				}
+			} else if c, ok := parseColorSpec(spec); ok {
+				g.setDynamicColor(index, c)
+			}
+		}
+	}
+}
+
+// handleResetColor handles OSC 104, which resets the given entries of the 256 color table or all of them.
+func (g *Window) handleResetColor(data string) {
+	if data == "" {
+		for index := range g.savedColors {
+			if index < 256 {
+				g.resetDynamicColor(index)
+			}
+		}
+		return
+	}
+
+	for _, arg := range strings.Split(data, ";") {
+		if index, err := strconv.Atoi(arg); err == nil && index >= 0 && index < 256 {
+			g.resetDynamicColor(index)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package crt
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"image/color"
+	"testing"
+)
+
+func TestParseColorSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want color.Color
+	}{
+		{"rgb:ff/80/00", color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}},
+		{"rgb:ffff/8080/0000", color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}},
+		{"rgb:f/8/0", color.RGBA{R: 0xff, G: 0x88, B: 0x00, A: 0xff}},
+		{"#ff8000", color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}},
+		{"#f80", color.RGBA{R: 0xff, G: 0x88, B: 0x00, A: 0xff}},
+		{"#ffff80800000", color.RGBA{R: 0xff, G: 0x80, B: 0x00, A: 0xff}},
+		{"rgb:ff/80", nil},
+		{"rgb:fffff/0/0", nil},
+		{"#ff80", nil},
+		{"red", nil},
+	}
+
+	for _, test := range tests {
+		c, ok := parseColorSpec(test.spec)
+		assert.Equal(t, test.want != nil, ok, test.spec)
+		assert.Equal(t, test.want, c, test.spec)
+	}
+}
+
+func TestDynamicColorQuery(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	var response bytes.Buffer
+	win.SetResponseWriter(&response)
+	win.SetTheme(Theme{Foreground: hexColor(0xc0c0c0), Background: hexColor(0x102030)})
+
+	win.feed([]byte(ESC + "]11;?" + ESC + "\\"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"]11;rgb:1010/2020/3030"+ESC+"\\", response.String())
+
+	response.Reset()
+	win.feed([]byte(ESC + "]10;?;?\a"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"]10;rgb:c0c0/c0c0/c0c0"+ESC+"\\"+ESC+"]11;rgb:1010/2020/3030"+ESC+"\\", response.String())
+
+	response.Reset()
+	win.feed([]byte(ESC + "]4;1;?;300;?\a"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"]4;1;rgb:8080/0000/0000"+ESC+"\\", response.String())
+}
+
+func TestDynamicColorSetReset(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	theme := win.GetTheme()
+
+	win.feed([]byte(ESC + "]10;#112233\a" + ESC + "]11;rgb:44/55/66\a" + ESC + "]12;#778899\a"))
+	assert.Equal(t, hexColor(0x112233), win.defaultFg)
+	assert.Equal(t, hexColor(0x445566), win.defaultBg)
+	assert.Equal(t, hexColor(0x778899), win.cursorColor)
+	assert.Equal(t, win.defaultBg, win.resolveColor(DefaultColor, false))
+
+	win.feed([]byte(ESC + "]4;1;#010203;200;#040506\a"))
+	assert.Equal(t, hexColor(0x010203), win.resolveColor(IndexedColor(1), true))
+	assert.Equal(t, hexColor(0x040506), win.resolveColor(IndexedColor(200), true))
+
+	win.feed([]byte(ESC + "]110\a" + ESC + "]111\a" + ESC + "]112\a"))
+	assert.Equal(t, theme.Foreground, win.defaultFg)
+	assert.Equal(t, theme.Background, win.defaultBg)
+	assert.Equal(t, theme.Cursor, win.cursorColor)
+
+	win.feed([]byte(ESC + "]104;1\a"))
+	assert.Equal(t, theme.Palette[1], win.resolveColor(IndexedColor(1), true))
+	assert.Equal(t, hexColor(0x040506), win.resolveColor(IndexedColor(200), true))
+
+	win.feed([]byte(ESC + "]104\a"))
+	assert.NotEqual(t, hexColor(0x040506), win.resolveColor(IndexedColor(200), true))
+}
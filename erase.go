@@ -0,0 +1,107 @@
+package crt
+
+// EraseDisplay erases parts of the screen like ED. The erased cells use the current background color.
+//
+// - 0: erase from the cursor to the end of the screen
+// - 1: erase from the start of the screen to the cursor
+// - 2: erase the entire screen
+// - 3: erase the scrollback, the visible screen is kept
+func (g *Window) EraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		g.EraseLine(0)
+		for y := g.cursorY + 1; y < g.cellsHeight; y++ {
+			g.eraseCells(y, 0, g.cellsWidth)
+		}
+	case 1:
+		for y := 0; y < g.cursorY; y++ {
+			g.eraseCells(y, 0, g.cellsWidth)
+		}
+		g.EraseLine(1)
+	case 2:
+		for y := 0; y < g.cellsHeight; y++ {
+			g.eraseCells(y, 0, g.cellsWidth)
+		}
+	case 3:
+		g.clearScrollback()
+	}
+}
+
+// EraseLine erases parts of the cursor line like EL. The erased cells use the current background color.
+//
+// - 0: erase from the cursor to the end of the line
+// - 1: erase from the start of the line to the cursor
+// - 2: erase the entire line
+func (g *Window) EraseLine(mode int) {
+	switch mode {
+	case 0:
+		g.eraseCells(g.cursorY, g.clampedCursorX(), g.cellsWidth)
+	case 1:
+		g.eraseCells(g.cursorY, 0, g.clampedCursorX()+1)
+	case 2:
+		g.eraseCells(g.cursorY, 0, g.cellsWidth)
+	}
+}
+
+// clampedCursorX returns the cursor column limited to the last cell. After printing
+// into the last column the cursor is one cell past the line until the next character wraps.
+func (g *Window) clampedCursorX() int {
+	if g.cursorX >= g.cellsWidth {
+		return g.cellsWidth - 1
+	}
+	return g.cursorX
+}
+
+// eraseCells blanks the cells of line y from start to end (exclusive).
+func (g *Window) eraseCells(y, start, end int) {
+	if start < 0 {
+		start = 0
+	}
+	if end > g.cellsWidth {
+		end = g.cellsWidth
+	}
+
+	for x := start; x < end; x++ {
+		g.grid[y][x] = g.blankCell()
+		g.syncBgPixels(x, y)
+	}
+
+	// Wide characters that were cut in half are removed.
+	g.fixWideCell(y, start-1)
+	g.fixWideCell(y, end)
+}
+
+// insertCells inserts n blank cells at column x of line y. Cells that are shifted
+// past the end of the line are discarded.
+func (g *Window) insertCells(y, x, n int) {
+	if n > g.cellsWidth-x {
+		n = g.cellsWidth - x
+	}
+
+	line := g.grid[y]
+	copy(line[x+n:], line[x:g.cellsWidth-n])
+	g.eraseCells(y, x, x+n)
+	g.fixWideCell(y, g.cellsWidth-1)
+	g.recalculateBackgroundCells(y, x+n, g.cellsWidth)
+}
+
+// deleteCells deletes n cells at column x of line y. The remaining cells are shifted
+// to the left and blank cells are inserted at the end of the line.
+func (g *Window) deleteCells(y, x, n int) {
+	if n > g.cellsWidth-x {
+		n = g.cellsWidth - x
+	}
+
+	line := g.grid[y]
+	copy(line[x:], line[x+n:])
+	g.fixWideCell(y, x)
+	g.recalculateBackgroundCells(y, x, g.cellsWidth-n)
+	g.eraseCells(y, g.cellsWidth-n, g.cellsWidth)
+}
+
+// recalculateBackgroundCells syncs the background pixels of line y from start to end (exclusive).
+func (g *Window) recalculateBackgroundCells(y, start, end int) {
+	for x := start; x < end; x++ {
+		g.syncBgPixels(x, y)
+	}
+}
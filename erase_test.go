@@ -0,0 +1,80 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEraseSequences(t *testing.T) {
+	tests := []struct {
+		name string
+		row  int
+		col  int
+		seq  string
+		want []string
+	}{
+		{"ED 0", 2, 3, termenv.CSI + "0J", []string{"abcde", "ab", "", ""}},
+		{"ED default", 2, 3, termenv.CSI + "J", []string{"abcde", "ab", "", ""}},
+		{"ED 1", 2, 3, termenv.CSI + "1J", []string{"", "   de", "abcde", "abcde"}},
+		{"ED 2", 2, 3, termenv.CSI + "2J", []string{"", "", "", ""}},
+		{"ED 3", 2, 3, termenv.CSI + "3J", []string{"abcde", "abcde", "abcde", "abcde"}},
+		{"EL 0", 2, 3, termenv.CSI + "0K", []string{"abcde", "ab", "abcde", "abcde"}},
+		{"EL default", 2, 3, termenv.CSI + "K", []string{"abcde", "ab", "abcde", "abcde"}},
+		{"EL 1", 2, 3, termenv.CSI + "1K", []string{"abcde", "   de", "abcde", "abcde"}},
+		{"EL 2", 2, 3, termenv.CSI + "2K", []string{"abcde", "", "abcde", "abcde"}},
+		{"EL 0 first column", 2, 1, termenv.CSI + "0K", []string{"abcde", "", "abcde", "abcde"}},
+		{"EL 1 last column", 2, 5, termenv.CSI + "1K", []string{"abcde", "", "abcde", "abcde"}},
+		{"ECH", 2, 2, termenv.CSI + "2X", []string{"abcde", "a  de", "abcde", "abcde"}},
+		{"ECH default", 2, 2, termenv.CSI + "X", []string{"abcde", "a cde", "abcde", "abcde"}},
+		{"ECH past end", 2, 4, termenv.CSI + "9X", []string{"abcde", "abc", "abcde", "abcde"}},
+		{"ICH", 2, 2, termenv.CSI + "2@", []string{"abcde", "a  bc", "abcde", "abcde"}},
+		{"ICH default", 2, 1, termenv.CSI + "@", []string{"abcde", " abcd", "abcde", "abcde"}},
+		{"ICH past end", 2, 4, termenv.CSI + "9@", []string{"abcde", "abc", "abcde", "abcde"}},
+		{"DCH", 2, 2, termenv.CSI + "2P", []string{"abcde", "ade", "abcde", "abcde"}},
+		{"DCH default", 2, 1, termenv.CSI + "P", []string{"abcde", "bcde", "abcde", "abcde"}},
+		{"DCH past end", 2, 4, termenv.CSI + "9P", []string{"abcde", "abc", "abcde", "abcde"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			win := newTestWindow(t, 5, 4)
+			win.feed([]byte("abcde\nabcde\nabcde\nabcde"))
+			win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, test.row, test.col)))
+			win.feed([]byte(test.seq))
+
+			assert.Equal(t, test.want, screenLines(win))
+			assert.Equal(t, test.row-1, win.cursorY)
+			assert.Equal(t, test.col-1, win.cursorX)
+		})
+	}
+}
+
+func TestEraseUsesCurrentBackground(t *testing.T) {
+	win := newTestWindow(t, 5, 2)
+	win.feed([]byte("abcde" + termenv.CSI + "48;2;255;10;10m"))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 3) + termenv.CSI + "K"))
+
+	for x := 0; x < 5; x++ {
+		if x < 2 {
+			assert.Equal(t, DefaultColor, win.grid[0][x].Bg)
+		} else {
+			assert.Equal(t, win.curBg, win.grid[0][x].Bg)
+		}
+	}
+
+	r, _, _, _ := win.bgColors.At(2*win.cellWidth, 0).RGBA()
+	assert.Equal(t, uint32(0xffff), r)
+}
+
+func TestEraseAfterLastColumn(t *testing.T) {
+	win := newTestWindow(t, 5, 2)
+
+	// The cursor waits past the last column until the next character is printed.
+	win.feed([]byte("abcde"))
+	assert.Equal(t, 5, win.cursorX)
+
+	win.feed([]byte(termenv.CSI + "K"))
+	assert.Equal(t, []string{"abcd", ""}, screenLines(win))
+}
@@ -0,0 +1,24 @@
+package crt
+
+// ESC is the escape character that starts all escape sequences.
+const ESC = "\x1b"
+
+type SetTabStopSeq struct{}
+
+// parseESC parses an escape sequence event and returns a struct representing the sequence.
+func parseESC(e ESCEvent) (any, bool) {
+	if e.Intermediates != "" {
+		return nil, false
+	}
+
+	switch e.Final {
+	case '7': // DECSC
+		return SaveCursorPositionSeq{}, true
+	case '8': // DECRC
+		return RestoreCursorPositionSeq{}, true
+	case 'H': // HTS
+		return SetTabStopSeq{}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,24 @@
+package crt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestESC(t *testing.T) {
+	testString := "HELLO" + ESC + "7WORLD" + ESC + "8" + ESC + "[1m" + ESC + "M"
+
+	var sequences []any
+	NewParser().Feed([]byte(testString), func(event any) {
+		if esc, ok := event.(ESCEvent); ok {
+			if res, ok := parseESC(esc); ok {
+				sequences = append(sequences, res)
+			}
+		}
+	})
+
+	assert.Equal(t, []any{
+		SaveCursorPositionSeq{},
+		RestoreCursorPositionSeq{},
+	}, sequences)
+}
@@ -1,6 +1,7 @@
 package crt
 
 import (
+	"errors"
 	"golang.org/x/image/font"
 	"golang.org/x/image/font/opentype"
 	"os"
@@ -10,6 +11,36 @@ type Fonts struct {
 	Normal font.Face
 	Bold   font.Face
 	Italic font.Face
+
+	// source is set if the faces were loaded by this package, so they can be re-created in a different size.
+	source *fontSource
+}
+
+// fontSource holds the font data and options the faces were created from.
+type fontSource struct {
+	normal []byte
+	bold   []byte
+	italic []byte
+	dpi    float64
+	size   float64
+}
+
+// Size returns the size the faces were loaded with or zero if the faces weren't loaded by LoadFaces or LoadFacesBytes.
+func (f Fonts) Size() float64 {
+	if f.source == nil {
+		return 0
+	}
+	return f.source.size
+}
+
+// WithSize re-creates the faces from the original font data in a different size.
+// This only works for fonts that were loaded by LoadFaces or LoadFacesBytes.
+func (f Fonts) WithSize(size float64) (Fonts, error) {
+	if f.source == nil {
+		return Fonts{}, errors.New("fonts were not loaded from font data")
+	}
+
+	return LoadFacesBytes(f.source.normal, f.source.bold, f.source.italic, f.source.dpi, size)
 }
 
 // LoadFaceBytes loads a font face from bytes. The dpi and size are used to generate the font face.
@@ -54,6 +85,13 @@ func LoadFacesBytes(normal []byte, bold []byte, italic []byte, dpi float64, size
 		Normal: normalFace,
 		Bold:   boldFace,
 		Italic: italicFace,
+		source: &fontSource{
+			normal: normal,
+			bold:   bold,
+			italic: italic,
+			dpi:    dpi,
+			size:   size,
+		},
 	}, nil
 }
 
@@ -72,24 +110,13 @@ func LoadFace(file string, dpi float64, size float64) (font.Face, error) {
 // LoadFaces loads a set of fonts from files. The normal, bold, and italic files
 // must be provided. The dpi and size are used to generate the font faces. Supports ttf and otf.
 func LoadFaces(normal string, bold string, italic string, dpi float64, size float64) (Fonts, error) {
-	normalFace, err := LoadFace(normal, dpi, size)
-	if err != nil {
-		return Fonts{}, err
+	var data [3][]byte
+	for i, file := range []string{normal, bold, italic} {
+		var err error
+		if data[i], err = os.ReadFile(file); err != nil {
+			return Fonts{}, err
+		}
 	}
 
-	boldFace, err := LoadFace(bold, dpi, size)
-	if err != nil {
-		return Fonts{}, err
-	}
-
-	italicFace, err := LoadFace(italic, dpi, size)
-	if err != nil {
-		return Fonts{}, err
-	}
-
-	return Fonts{
-		Normal: normalFace,
-		Bold:   boldFace,
-		Italic: italicFace,
-	}, nil
+	return LoadFacesBytes(data[0], data[1], data[2], dpi, size)
 }
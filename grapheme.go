@@ -0,0 +1,105 @@
+package crt
+
+import (
+	"github.com/rivo/uniseg"
+	"unicode/utf8"
+)
+
+// graphemeBase is the first value of GridCell.Char that refers to a grapheme cluster of multiple
+// runes (e.g. e + U+0301 or a ZWJ emoji sequence). It lies above the range of unicode code points,
+// so single runes are stored as they are and the cell stays compact.
+const graphemeBase rune = utf8.MaxRune + 1
+
+// minGraphemeLimit is the number of clusters a table holds before unused clusters are dropped.
+const minGraphemeLimit = 1024
+
+// graphemeTable interns the grapheme clusters that are stored in the cells of a window.
+type graphemeTable struct {
+	clusters []string
+	ids      map[string]rune
+	limit    int
+}
+
+// intern returns the value of GridCell.Char for a grapheme cluster.
+func (t *graphemeTable) intern(cluster string) rune {
+	if r, size := utf8.DecodeRuneInString(cluster); size == len(cluster) {
+		return r
+	}
+	if id, ok := t.ids[cluster]; ok {
+		return id
+	}
+
+	if t.ids == nil {
+		t.ids = map[string]rune{}
+	}
+	id := graphemeBase + rune(len(t.clusters))
+	t.clusters = append(t.clusters, cluster)
+	t.ids[cluster] = id
+	return id
+}
+
+// text returns the text of a GridCell.Char value.
+func (t *graphemeTable) text(r rune) string {
+	if r < graphemeBase {
+		return string(r)
+	}
+	return t.clusters[r-graphemeBase]
+}
+
+// baseRune returns the first rune of a GridCell.Char value.
+func (t *graphemeTable) baseRune(r rune) rune {
+	if r < graphemeBase {
+		return r
+	}
+
+	base, _ := utf8.DecodeRuneInString(t.text(r))
+	return base
+}
+
+// cellText returns the grapheme cluster of the cell. The right half of a wide character has no text.
+func (g *Window) cellText(c GridCell) string {
+	if c.Char == wideSpacer {
+		return ""
+	}
+	return g.graphemes.text(c.Char)
+}
+
+// compactGraphemes drops the clusters that are no longer stored in a screen or the scrollback
+// and renumbers the others. The limit grows with the clusters in use, so this happens rarely.
+func (g *Window) compactGraphemes() {
+	compacted := graphemeTable{}
+	g.forEachLine(func(line []GridCell) {
+		for x := range line {
+			if line[x].Char >= graphemeBase {
+				line[x].Char = compacted.intern(g.graphemes.text(line[x].Char))
+			}
+		}
+	})
+
+	compacted.limit = len(compacted.clusters) * 2
+	if compacted.limit < minGraphemeLimit {
+		compacted.limit = minGraphemeLimit
+	}
+	g.graphemes = compacted
+}
+
+// extendGrapheme appends r to the grapheme cluster of the last printed cell and returns true
+// if r continues that cluster (e.g. a combining mark, a variation selector or a ZWJ sequence).
+func (g *Window) extendGrapheme(r rune) bool {
+	// Runes below the combining diacritical marks always start a new cluster.
+	if g.lastCell == nil || r < 0x300 {
+		return false
+	}
+
+	cluster := g.cellText(*g.lastCell) + string(r)
+	if uniseg.GraphemeClusterCount(cluster) != 1 {
+		return false
+	}
+
+	if len(g.graphemes.clusters) >= g.graphemes.limit {
+		g.compactGraphemes()
+	}
+	g.lastCell.Char = g.graphemes.intern(cluster)
+	g.InvalidateBuffer()
+	return true
+}
@@ -0,0 +1,69 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGraphemeClusters(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		input  string
+		cells  []string
+		cursor int
+	}{
+		{"combining mark", "éx", []string{"é", "x"}, 2},
+		{"multiple marks", "ä́", []string{"ä́"}, 1},
+		{"variation selector", "❤️!", []string{"❤️", "!"}, 2},
+		{"zwj sequence", "👨‍👩‍👧x", []string{"👨‍👩‍👧", "", "x"}, 3},
+		// go-runewidth, which is used by bubbletea, counts flags as one cell.
+		{"flag", "🇩🇪x", []string{"🇩🇪", "x"}, 2},
+		{"attributes inside cluster", "e" + termenv.CSI + "1ḿ", []string{"é"}, 1},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			win := newTestWindow(t, 10, 2)
+			win.feed([]byte(test.input))
+
+			for x, cell := range test.cells {
+				assert.Equal(t, cell, win.cellText(win.grid[0][x]), x)
+			}
+			assert.Equal(t, test.cursor, win.cursorX)
+		})
+	}
+}
+
+func TestGraphemeClusterInterrupted(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+
+	// A cursor movement ends the cluster, so the mark on its own is dropped.
+	win.feed([]byte("e" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 5) + "́"))
+	assert.Equal(t, "e", win.cellText(win.grid[0][0]))
+	assert.Equal(t, 4, win.cursorX)
+}
+
+func TestInternGrapheme(t *testing.T) {
+	var table graphemeTable
+	assert.Equal(t, 'a', table.intern("a"))
+
+	id := table.intern("e\u0301")
+	assert.GreaterOrEqual(t, id, graphemeBase)
+	assert.Equal(t, id, table.intern("e\u0301"))
+	assert.Equal(t, "e\u0301", table.text(id))
+	assert.Equal(t, 'e', table.baseRune(id))
+}
+
+func TestCompactGraphemes(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.SetScrollbackSize(1)
+
+	// Clusters that scrolled out of the scrollback are dropped, the ones still shown are kept.
+	for i := 0; i < minGraphemeLimit*2; i++ {
+		win.feed([]byte(fmt.Sprintf("\r\n%c\u0301", 0x4e00+i)))
+	}
+	assert.LessOrEqual(t, len(win.graphemes.clusters), minGraphemeLimit)
+	assert.Equal(t, string(rune(0x4e00+minGraphemeLimit*2-1))+"\u0301", win.cellText(win.grid[1][0]))
+	assert.Equal(t, string(rune(0x4e00+minGraphemeLimit*2-2))+"\u0301", win.cellText(win.grid[0][0]))
+	assert.Equal(t, []string{string(rune(0x4e00+minGraphemeLimit*2-3)) + "\u0301"}, scrollbackLines(win))
+}
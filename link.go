@@ -0,0 +1,145 @@
+package crt
+
+import (
+	"math"
+	"strings"
+)
+
+// minLinkLimit is the number of links a table holds before unused links are dropped.
+const minLinkLimit = 1024
+
+// linkTable interns the targets of hyperlinks, so a cell only has to store a small id.
+// The id zero means no link. Links with the same target and id share one entry.
+type linkTable struct {
+	keys  []string
+	ids   map[string]uint16
+	limit int
+}
+
+// intern returns the id of the link. If the table is full zero is returned and the text isn't linked.
+func (t *linkTable) intern(id, url string) uint16 {
+	return t.internKey(id + ";" + url)
+}
+
+// internKey returns the id of the link with the given key (id;url).
+func (t *linkTable) internKey(key string) uint16 {
+	if link, ok := t.ids[key]; ok {
+		return link
+	}
+	if len(t.keys) >= math.MaxUint16 {
+		return 0
+	}
+
+	if t.ids == nil {
+		t.ids = map[string]uint16{}
+	}
+	t.keys = append(t.keys, key)
+	t.ids[key] = uint16(len(t.keys))
+	return uint16(len(t.keys))
+}
+
+// url returns the target of the link.
+func (t *linkTable) url(link uint16) string {
+	if link == 0 || int(link) > len(t.keys) {
+		return ""
+	}
+	_, url, _ := strings.Cut(t.keys[link-1], ";")
+	return url
+}
+
+// compactLinks drops the links that are no longer used by a cell of the screens or the scrollback
+// and renumbers the others. The limit grows with the links in use, so this happens rarely.
+func (g *Window) compactLinks() {
+	compacted := linkTable{}
+	remap := func(link uint16) uint16 {
+		if link == 0 || int(link) > len(g.links.keys) {
+			return 0
+		}
+		return compacted.internKey(g.links.keys[link-1])
+	}
+
+	g.forEachLine(func(line []GridCell) {
+		for x := range line {
+			line[x].Link = remap(line[x].Link)
+		}
+	})
+	g.curLink = remap(g.curLink)
+	g.hoverLink = remap(g.hoverLink)
+
+	compacted.limit = len(compacted.keys) * 2
+	if compacted.limit < minLinkLimit {
+		compacted.limit = minLinkLimit
+	}
+	if compacted.limit > math.MaxUint16 {
+		compacted.limit = math.MaxUint16
+	}
+	g.links = compacted
+}
+
+// SetOnLinkActivate sets a function that is called when a hyperlink is clicked with Ctrl held.
+// Links are not opened by default, so the host decides what to do with the url. A nil
+// function removes the callback.
+func (g *Window) SetOnLinkActivate(fn func(url string)) {
+	if fn == nil {
+		fn = func(url string) {}
+	}
+	g.onLinkActivate = fn
+}
+
+// GetLinkAt returns the target of the hyperlink at the given cell of the view or an empty string.
+func (g *Window) GetLinkAt(x, y int) string {
+	if x < 0 || y < 0 || y >= g.cellsHeight {
+		return ""
+	}
+	cell, ok := g.viewCell(x, y)
+	if !ok {
+		return ""
+	}
+	return g.links.url(cell.Link)
+}
+
+// setHyperlink handles OSC 8 (params;url). The following text links to the url until a link
+// with an empty url ends it. Text with the same id parameter and url belongs to the same link.
+func (g *Window) setHyperlink(data string) {
+	params, url, _ := strings.Cut(data, ";")
+	if url == "" {
+		g.curLink = 0
+		return
+	}
+
+	id := ""
+	for _, param := range strings.Split(params, ":") {
+		if key, val, _ := strings.Cut(param, "="); key == "id" {
+			id = val
+		}
+	}
+	if len(g.links.keys) >= g.links.limit {
+		g.compactLinks()
+	}
+	g.curLink = g.links.intern(id, url)
+}
+
+// updateHoverLink tracks the hyperlink below the mouse, so it can be underlined.
+func (g *Window) updateHoverLink() {
+	var link uint16
+	if x, y := g.mouseCellX, g.mouseCellY; x >= 0 && x < g.cellsWidth && y >= 0 && y < g.cellsHeight {
+		if cell, ok := g.viewCell(x, y); ok {
+			link = cell.Link
+		}
+	}
+
+	if link != g.hoverLink {
+		g.hoverLink = link
+		g.InvalidateBuffer()
+	}
+}
+
+// activateLink calls the link callback if there is a hyperlink at the given cell.
+func (g *Window) activateLink(x, y int) bool {
+	url := g.GetLinkAt(x, y)
+	if url == "" {
+		return false
+	}
+	g.onLinkActivate(url)
+	return true
+}
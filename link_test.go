@@ -0,0 +1,74 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestHyperlink(t *testing.T) {
+	win := newTestWindow(t, 20, 2)
+
+	win.feed([]byte("a " + ESC + "]8;;https://example.com\aLink" + ESC + "]8;;\a b"))
+	assert.Equal(t, []string{"a Link b", ""}, screenLines(win))
+
+	assert.Equal(t, "", win.GetLinkAt(0, 0))
+	for x := 2; x < 6; x++ {
+		assert.Equal(t, "https://example.com", win.GetLinkAt(x, 0))
+	}
+	assert.Equal(t, "", win.GetLinkAt(7, 0))
+	assert.Equal(t, "", win.GetLinkAt(-1, 5))
+}
+
+func TestHyperlinkIds(t *testing.T) {
+	win := newTestWindow(t, 20, 2)
+
+	win.feed([]byte(ESC + "]8;id=1;https://a.com\aA" + ESC + "]8;;\a "))
+	win.feed([]byte(ESC + "]8;id=1;https://a.com\aA" + ESC + "]8;;\a "))
+	win.feed([]byte(ESC + "]8;id=2;https://a.com" + ESC + "\\B" + ESC + "]8;;" + ESC + "\\"))
+
+	assert.NotZero(t, win.grid[0][0].Link)
+	assert.Equal(t, win.grid[0][0].Link, win.grid[0][2].Link)
+	assert.NotEqual(t, win.grid[0][0].Link, win.grid[0][4].Link)
+	assert.Equal(t, "https://a.com", win.GetLinkAt(4, 0))
+}
+
+func TestHyperlinkActivate(t *testing.T) {
+	win := newTestWindow(t, 20, 2)
+
+	var activated []string
+	win.SetOnLinkActivate(func(url string) {
+		activated = append(activated, url)
+	})
+
+	win.feed([]byte(ESC + "]8;;https://example.com\aLink" + ESC + "]8;;\a"))
+
+	win.mouseCellX, win.mouseCellY = 1, 0
+	win.updateHoverLink()
+	assert.Equal(t, win.grid[0][1].Link, win.hoverLink)
+
+	win.mouseCellX = 10
+	win.updateHoverLink()
+	assert.Zero(t, win.hoverLink)
+
+	assert.True(t, win.activateLink(3, 0))
+	assert.False(t, win.activateLink(4, 0))
+	assert.Equal(t, []string{"https://example.com"}, activated)
+
+	win.SetOnLinkActivate(nil)
+	assert.NotPanics(t, func() { win.activateLink(3, 0) })
+}
+
+func TestHyperlinkOverflow(t *testing.T) {
+	win := newTestWindow(t, 20, 2)
+
+	// Links that were overwritten are dropped, so the ids don't run out.
+	win.feed([]byte(ESC + "]8;;https://kept.com\aK" + ESC + "]8;;\a"))
+	for i := 0; i <= math.MaxUint16; i++ {
+		win.feed([]byte(fmt.Sprintf(ESC+"[2H"+ESC+"]8;;https://%d.com\aL"+ESC+"]8;;\a", i)))
+	}
+	assert.LessOrEqual(t, len(win.links.keys), minLinkLimit)
+	assert.Equal(t, "https://kept.com", win.GetLinkAt(0, 0))
+	assert.Equal(t, fmt.Sprintf("https://%d.com", math.MaxUint16), win.GetLinkAt(0, 1))
+}
@@ -0,0 +1,109 @@
+package crt
+
+import "github.com/hajimehoshi/ebiten/v2"
+
+// MouseMode is the mouse tracking mode that was requested by the application.
+type MouseMode int
+
+const (
+	// MouseModeNone doesn't report the mouse. The wheel scrolls through the scrollback instead.
+	MouseModeNone MouseMode = iota
+
+	// MouseModeX10 reports button presses (?9).
+	MouseModeX10
+
+	// MouseModeNormal reports button presses, releases and the wheel (?1000).
+	MouseModeNormal
+
+	// MouseModeButton additionally reports motion while a button is pressed (?1002).
+	MouseModeButton
+
+	// MouseModeAny additionally reports all motion (?1003).
+	MouseModeAny
+)
+
+// mouseModes maps the DEC private modes to the mouse tracking modes.
+var mouseModes = map[int]MouseMode{
+	9:    MouseModeX10,
+	1000: MouseModeNormal,
+	1002: MouseModeButton,
+	1003: MouseModeAny,
+}
+
+// GetMouseMode returns the mouse tracking mode that was requested by the application.
+func (g *Window) GetMouseMode() MouseMode {
+	return g.mouseMode
+}
+
+// GetMouseSGR returns true if the application requested the SGR encoding (?1006) of mouse reports.
+func (g *Window) GetMouseSGR() bool {
+	return g.mouseSGR
+}
+
+// setMouseMode enables or disables a mouse tracking mode. Only one mode is active at a time,
+// so disabling a mode that isn't active does nothing.
+func (g *Window) setMouseMode(mode MouseMode, val bool) {
+	if val {
+		g.mouseMode = mode
+	} else if g.mouseMode == mode {
+		g.mouseMode = MouseModeNone
+	}
+}
+
+// reportsMouseMotion returns true if the mouse motion is passed to the application.
+func (g *Window) reportsMouseMotion(buttonPressed bool) bool {
+	return g.mouseMode == MouseModeAny || (g.mouseMode == MouseModeButton && buttonPressed)
+}
+
+// reportsMouseButton returns true if the press or release of a button is passed to the application.
+func (g *Window) reportsMouseButton(release bool) bool {
+	if release {
+		return g.mouseMode >= MouseModeNormal
+	}
+	return g.mouseMode >= MouseModeX10
+}
+
+// reportsMouseWheel returns true if the mouse wheel is passed to the application.
+func (g *Window) reportsMouseWheel() bool {
+	return g.mouseMode >= MouseModeNormal
+}
+
+// handleMouseButton passes the press or release of a mouse button to the input adapter. Ctrl+click
+// on a hyperlink activates it and neither the press nor the release are passed on.
+func (g *Window) handleMouseButton(button ebiten.MouseButton, pressed bool) {
+	if button == ebiten.MouseButtonLeft {
+		if !pressed && g.linkPressed {
+			g.linkPressed = false
+			return
+		}
+		if pressed && ebiten.IsKeyPressed(ebiten.KeyControl) && g.activateLink(g.mouseCellX, g.mouseCellY) {
+			g.linkPressed = true
+			return
+		}
+	}
+
+	if !g.reportsMouseButton(!pressed) {
+		return
+	}
+
+	g.inputAdapter.HandleMouseButton(MouseButton{
+		X:            g.mouseCellX,
+		Y:            g.mouseCellY,
+		Shift:        ebiten.IsKeyPressed(ebiten.KeyShift),
+		Alt:          ebiten.IsKeyPressed(ebiten.KeyAlt),
+		Ctrl:         ebiten.IsKeyPressed(ebiten.KeyControl),
+		Button:       button,
+		JustPressed:  pressed,
+		JustReleased: !pressed,
+	})
+}
+
+// pressedMouseButton returns the first mouse button that is held down.
+func pressedMouseButton() (ebiten.MouseButton, bool) {
+	for b := ebiten.MouseButton0; b <= ebiten.MouseButtonMax; b++ {
+		if ebiten.IsMouseButtonPressed(b) {
+			return b, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,61 @@
+package crt
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMouseModes(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	assert.Equal(t, MouseModeNone, win.GetMouseMode())
+	assert.False(t, win.reportsMouseButton(false))
+	assert.False(t, win.reportsMouseWheel())
+	assert.False(t, win.reportsMouseMotion(true))
+
+	win.feed([]byte(ESC + "[?9h"))
+	assert.Equal(t, MouseModeX10, win.GetMouseMode())
+	assert.True(t, win.reportsMouseButton(false))
+	assert.False(t, win.reportsMouseButton(true))
+	assert.False(t, win.reportsMouseWheel())
+
+	win.feed([]byte(ESC + "[?1000h"))
+	assert.Equal(t, MouseModeNormal, win.GetMouseMode())
+	assert.True(t, win.reportsMouseButton(true))
+	assert.True(t, win.reportsMouseWheel())
+	assert.False(t, win.reportsMouseMotion(true))
+
+	win.feed([]byte(ESC + "[?1002h"))
+	assert.Equal(t, MouseModeButton, win.GetMouseMode())
+	assert.True(t, win.reportsMouseMotion(true))
+	assert.False(t, win.reportsMouseMotion(false))
+
+	win.feed([]byte(ESC + "[?1003h"))
+	assert.Equal(t, MouseModeAny, win.GetMouseMode())
+	assert.True(t, win.reportsMouseMotion(false))
+
+	// Disabling a mode that isn't active keeps the current mode.
+	win.feed([]byte(ESC + "[?1002l"))
+	assert.Equal(t, MouseModeAny, win.GetMouseMode())
+
+	win.feed([]byte(ESC + "[?1003l"))
+	assert.Equal(t, MouseModeNone, win.GetMouseMode())
+	assert.False(t, win.reportsMouseMotion(true))
+}
+
+func TestMouseSGR(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	var response bytes.Buffer
+	win.SetResponseWriter(&response)
+
+	win.feed([]byte(ESC + "[?1002;1006h"))
+	assert.Equal(t, MouseModeButton, win.GetMouseMode())
+	assert.True(t, win.GetMouseSGR())
+
+	win.feed([]byte(ESC + "[?1006$p" + ESC + "[?1002$p" + ESC + "[?1003$p"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"[?1006;1$y"+ESC+"[?1002;1$y"+ESC+"[?1003;2$y", response.String())
+
+	win.feed([]byte(ESC + "[?1006l"))
+	assert.False(t, win.GetMouseSGR())
+}
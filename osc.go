@@ -0,0 +1,99 @@
+package crt
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// OSCHandler handles the data of an operating system command. The data is everything after the first ';'.
+type OSCHandler func(data string)
+
+// parseOSC splits the data of an operating system command into the numeric code and the data.
+func parseOSC(s string) (int, string, bool) {
+	codeStr, data, _ := strings.Cut(s, ";")
+	code, err := strconv.Atoi(codeStr)
+	if err != nil || code < 0 {
+		return 0, "", false
+	}
+	return code, data, true
+}
+
+// RegisterOSCHandler sets the handler for an operating system command (ESC ] code ; data BEL). This
+// can be used to send out-of-band messages from the application to the host. The built-in handlers
+// (e.g. 0 and 2 for the window title) can be replaced. A nil handler removes the handler.
+func (g *Window) RegisterOSCHandler(code int, fn OSCHandler) {
+	if fn == nil {
+		delete(g.oscHandlers, code)
+		return
+	}
+	g.oscHandlers[code] = fn
+}
+
+// registerDefaultOSCHandlers registers the built-in handlers of the operating system commands.
+func (g *Window) registerDefaultOSCHandlers() {
+	g.oscHandlers = map[int]OSCHandler{
+		0: func(data string) {
+			g.iconName = data
+			g.SetTitle(data)
+		},
+		1: func(data string) {
+			g.iconName = data
+		},
+		2:   g.SetTitle,
+		4:   g.handleColor,
+		7:   g.setWorkingDirectory,
+		8:   g.setHyperlink,
+		10:  g.handleDynamicColors(10),
+		11:  g.handleDynamicColors(11),
+		12:  g.handleDynamicColors(12),
+		52:  g.handleClipboard,
+		104: g.handleResetColor,
+		110: func(string) { g.resetDynamicColor(dynamicFg) },
+		111: func(string) { g.resetDynamicColor(dynamicBg) },
+		112: func(string) { g.resetDynamicColor(dynamicCursor) },
+	}
+}
+
+// handleOSC calls the handler of an operating system command. Unknown commands are ignored.
+func (g *Window) handleOSC(s string) {
+	code, data, ok := parseOSC(s)
+	if !ok {
+		return
+	}
+
+	if fn, ok := g.oscHandlers[code]; ok {
+		fn(data)
+	}
+}
+
+// SetTitle sets the title of the window.
+func (g *Window) SetTitle(title string) {
+	g.title = title
+	ebiten.SetWindowTitle(title)
+}
+
+// GetTitle returns the title of the window.
+func (g *Window) GetTitle() string {
+	return g.title
+}
+
+// GetIconName returns the icon name that was set by the application with OSC 0 or 1.
+func (g *Window) GetIconName() string {
+	return g.iconName
+}
+
+// GetWorkingDirectory returns the working directory that was reported by the application with OSC 7.
+func (g *Window) GetWorkingDirectory() string {
+	return g.workingDirectory
+}
+
+// setWorkingDirectory handles OSC 7, which reports the working directory as file URL (e.g. file://host/home/user).
+func (g *Window) setWorkingDirectory(data string) {
+	u, err := url.Parse(data)
+	if err != nil || u.Scheme != "file" {
+		return
+	}
+	g.workingDirectory = u.Path
+}
@@ -0,0 +1,66 @@
+package crt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseOSC(t *testing.T) {
+	code, data, ok := parseOSC("2;my title")
+	assert.True(t, ok)
+	assert.Equal(t, 2, code)
+	assert.Equal(t, "my title", data)
+
+	code, data, ok = parseOSC("104")
+	assert.True(t, ok)
+	assert.Equal(t, 104, code)
+	assert.Equal(t, "", data)
+
+	_, _, ok = parseOSC("title")
+	assert.False(t, ok)
+}
+
+func TestOSCTitle(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+
+	win.feed([]byte(ESC + "]0;both\a"))
+	assert.Equal(t, "both", win.GetTitle())
+	assert.Equal(t, "both", win.GetIconName())
+
+	win.feed([]byte(ESC + "]2;title; with semicolon" + ESC + "\\"))
+	assert.Equal(t, "title; with semicolon", win.GetTitle())
+	assert.Equal(t, "both", win.GetIconName())
+
+	win.feed([]byte(ESC + "]1;icon\a"))
+	assert.Equal(t, "icon", win.GetIconName())
+
+	// Nothing leaks into the grid.
+	assert.Equal(t, []string{"", ""}, screenLines(win))
+}
+
+func TestOSCWorkingDirectory(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+
+	win.feed([]byte(ESC + "]7;file://host/home/user/my%20dir\a"))
+	assert.Equal(t, "/home/user/my dir", win.GetWorkingDirectory())
+
+	win.feed([]byte(ESC + "]7;not a url\a"))
+	assert.Equal(t, "/home/user/my dir", win.GetWorkingDirectory())
+}
+
+func TestRegisterOSCHandler(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+
+	var received []string
+	win.RegisterOSCHandler(1337, func(data string) {
+		received = append(received, data)
+	})
+
+	win.feed([]byte(ESC + "]1337;score=10\a" + ESC + "]1338;ignored\a"))
+	assert.Equal(t, []string{"score=10"}, received)
+
+	// Built-in handlers can be replaced and removed.
+	win.RegisterOSCHandler(2, nil)
+	win.feed([]byte(ESC + "]2;title\a"))
+	assert.Equal(t, "", win.GetTitle())
+}
@@ -0,0 +1,81 @@
+package crt
+
+import (
+	"github.com/lucasb-eyer/go-colorful"
+	"github.com/muesli/termenv"
+	"image/color"
+)
+
+// Palette holds the 16 base colors of the terminal. The first 8 colors are the normal
+// ANSI colors (black, red, green, yellow, blue, magenta, cyan, white) followed by their bright variants.
+type Palette [16]color.Color
+
+// DefaultPalette is the palette that is used if no other palette is set.
+var DefaultPalette = Palette{
+	color.RGBA{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+	color.RGBA{R: 0x80, G: 0x00, B: 0x00, A: 0xff},
+	color.RGBA{R: 0x00, G: 0x80, B: 0x00, A: 0xff},
+	color.RGBA{R: 0x80, G: 0x80, B: 0x00, A: 0xff},
+	color.RGBA{R: 0x00, G: 0x00, B: 0x80, A: 0xff},
+	color.RGBA{R: 0x80, G: 0x00, B: 0x80, A: 0xff},
+	color.RGBA{R: 0x00, G: 0x80, B: 0x80, A: 0xff},
+	color.RGBA{R: 0xc0, G: 0xc0, B: 0xc0, A: 0xff},
+	color.RGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff},
+	color.RGBA{R: 0xff, G: 0x00, B: 0x00, A: 0xff},
+	color.RGBA{R: 0x00, G: 0xff, B: 0x00, A: 0xff},
+	color.RGBA{R: 0xff, G: 0xff, B: 0x00, A: 0xff},
+	color.RGBA{R: 0x00, G: 0x00, B: 0xff, A: 0xff},
+	color.RGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff},
+	color.RGBA{R: 0x00, G: 0xff, B: 0xff, A: 0xff},
+	color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+}
+
+// ansi256Colors holds the colors of the 256 color table above the base palette. It is
+// computed once and never changed, so all windows can read it at the same time.
+var ansi256Colors = func() (colors [256]color.Color) {
+	for id := 16; id < len(colors); id++ {
+		if col, err := colorful.Hex(termenv.ANSI256Color(id).String()); err == nil {
+			colors[id] = col
+		}
+	}
+	return colors
+}()
+
+// SetPalette sets the 16 base colors that are used for the ANSI color sequences.
+// Existing cells that use a palette color are drawn with the new color.
+func (g *Window) SetPalette(palette Palette) {
+	g.palette = palette
+	g.RecalculateBackgrounds()
+	g.InvalidateBuffer()
+}
+
+// GetPalette returns the 16 base colors that are used for the ANSI color sequences.
+func (g *Window) GetPalette() Palette {
+	return g.palette
+}
+
+// SetDefaultFg sets the foreground color that is used if no color is selected.
+func (g *Window) SetDefaultFg(c color.Color) {
+	g.defaultFg = c
+
+	// Reversed cells use the foreground color as background.
+	g.RecalculateBackgrounds()
+	g.InvalidateBuffer()
+}
+
+// ansiColor returns the color of an entry in the 256 color table. The first 16
+// entries are taken from the palette.
+func (g *Window) ansiColor(id int) (color.Color, bool) {
+	if id >= 0 && id < len(g.palette) {
+		return g.palette[id], true
+	}
+
+	if val, ok := g.colorOverrides[id]; ok {
+		return val, true
+	}
+
+	if id >= 0 && id < len(ansi256Colors) && ansi256Colors[id] != nil {
+		return ansi256Colors[id], true
+	}
+	return nil, false
+}
@@ -0,0 +1,88 @@
+package crt
+
+import (
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"image/color"
+	"sync"
+	"testing"
+)
+
+func TestANSIColors(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.feed([]byte(termenv.CSI + "31;42mA" + termenv.CSI + "91;102mB" + termenv.CSI + "39;49mC" + termenv.CSI + "38;5;4mD"))
+
+	assert.Equal(t, IndexedColor(1), win.grid[0][0].Fg)
+	assert.Equal(t, IndexedColor(2), win.grid[0][0].Bg)
+	assert.Equal(t, IndexedColor(9), win.grid[0][1].Fg)
+	assert.Equal(t, IndexedColor(10), win.grid[0][1].Bg)
+	assert.Equal(t, DefaultColor, win.grid[0][2].Fg)
+	assert.Equal(t, DefaultColor, win.grid[0][2].Bg)
+	assert.Equal(t, IndexedColor(4), win.grid[0][3].Fg)
+
+	fg, bg := win.cellColors(win.grid[0][0])
+	assert.Equal(t, DefaultPalette[1], fg)
+	assert.Equal(t, DefaultPalette[2], bg)
+}
+
+func TestCustomPalette(t *testing.T) {
+	amber := color.RGBA{R: 0xff, G: 0xb0, B: 0x00, A: 0xff}
+	red := color.RGBA{R: 0xcc, G: 0x22, B: 0x22, A: 0xff}
+
+	win := newTestWindow(t, 10, 2)
+
+	palette := win.GetPalette()
+	palette[1] = red
+	win.SetPalette(palette)
+	win.SetDefaultFg(amber)
+
+	win.feed([]byte(termenv.CSI + "31mA" + termenv.CSI + "0mB" + termenv.CSI + "31;39mC"))
+
+	for i, want := range []color.Color{red, amber, amber} {
+		fg, _ := win.cellColors(win.grid[0][i])
+		assert.Equal(t, want, fg)
+	}
+}
+
+func TestSetPaletteUpdatesBackgrounds(t *testing.T) {
+	red := color.RGBA{R: 0xcc, G: 0x22, B: 0x22, A: 0xff}
+	amber := color.RGBA{R: 0xff, G: 0xb0, B: 0x00, A: 0xff}
+
+	win := newTestWindow(t, 10, 2)
+	win.feed([]byte(termenv.CSI + "41mA" + termenv.CSI + "0;7mB"))
+	win.invalidateBuffer = false
+
+	palette := win.GetPalette()
+	palette[1] = red
+	win.SetPalette(palette)
+	assert.Equal(t, red, win.bgColors.RGBAAt(0, 0))
+	assert.True(t, win.invalidateBuffer)
+
+	win.invalidateBuffer = false
+	win.SetDefaultFg(amber)
+	assert.Equal(t, amber, win.bgColors.RGBAAt(win.cellWidth, 0))
+	assert.True(t, win.invalidateBuffer)
+}
+
+func TestANSIColorsConcurrent(t *testing.T) {
+	// The color table is shared by all windows.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		win := newTestWindow(t, 10, 2)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := 0; id < 256; id++ {
+				_, ok := win.ansiColor(id)
+				assert.True(t, ok, id)
+			}
+		}()
+	}
+	wg.Wait()
+
+	win := newTestWindow(t, 10, 2)
+	col, _ := win.ansiColor(196)
+	assert.Equal(t, color.RGBAModel.Convert(color.RGBA{R: 0xff, A: 0xff}), color.RGBAModel.Convert(col))
+	_, ok := win.ansiColor(256)
+	assert.False(t, ok)
+}
@@ -0,0 +1,408 @@
+package crt
+
+import (
+	"github.com/muesli/termenv"
+	"unicode/utf8"
+)
+
+const (
+	// maxParamsLength is the maximum length of the parameter bytes of a CSI or DCS sequence.
+	// Longer sequences are ignored.
+	maxParamsLength = 256
+
+	// maxIntermediates is the maximum number of intermediate bytes of a sequence.
+	// Sequences with more intermediates are ignored.
+	maxIntermediates = 2
+
+	// maxStringLength is the maximum length of the data of an OSC or DCS string.
+	// Data beyond this limit is dropped.
+	maxStringLength = 1 << 20
+)
+
+// PrintEvent is emitted for every printable character.
+type PrintEvent struct {
+	Rune rune
+}
+
+// ControlEvent is emitted for every C0 control character (e.g. '\n' or BEL).
+type ControlEvent struct {
+	Code byte
+}
+
+// ESCEvent is emitted for escape sequences that are not CSI, OSC or DCS (e.g. ESC 7).
+type ESCEvent struct {
+	Intermediates string
+	Final         byte
+}
+
+// CSIEvent is emitted for control sequences (e.g. ESC [ 1 ; 2 H).
+type CSIEvent struct {
+	Prefix        byte
+	Params        string
+	Intermediates string
+	Final         byte
+}
+
+// OSCEvent is emitted for operating system commands (e.g. ESC ] 0 ; title BEL).
+type OSCEvent struct {
+	Data string
+}
+
+// DCSEvent is emitted for device control strings (e.g. ESC P $ q m ESC \).
+type DCSEvent struct {
+	Prefix        byte
+	Params        string
+	Intermediates string
+	Final         byte
+	Data          string
+}
+
+// String returns the sequence in its ESC [ form.
+func (e CSIEvent) String() string {
+	s := termenv.CSI
+	if e.Prefix != 0 {
+		s += string(e.Prefix)
+	}
+	return s + e.Params + e.Intermediates + string(e.Final)
+}
+
+// IsSGR returns true if the event is a select graphic rendition sequence.
+func (e CSIEvent) IsSGR() bool {
+	return e.Final == 'm' && e.Prefix == 0 && e.Intermediates == ""
+}
+
+type parserState byte
+
+const (
+	stateGround parserState = iota
+	stateEscape
+	stateEscapeIntermediate
+	stateCSIEntry
+	stateCSIParam
+	stateCSIIntermediate
+	stateCSIIgnore
+	stateDCSEntry
+	stateDCSParam
+	stateDCSIntermediate
+	stateDCSPassthrough
+	stateDCSIgnore
+	stateOSCString
+	stateSOSPMAPCString
+)
+
+// Parser is an incremental parser for the output of terminal applications. It is based on the
+// DEC compatible state machine by Paul Williams (https://vt100.net/emu/dec_ansi_parser).
+//
+// The state of incomplete sequences and characters is kept between calls to Feed, so
+// the input can be split at arbitrary positions.
+type Parser struct {
+	state         parserState
+	prefix        byte
+	params        []byte
+	intermediates []byte
+	ignore        bool
+	data          []byte
+	utf8          [utf8.UTFMax]byte
+	utf8Len       int
+}
+
+// NewParser creates a new parser in the ground state.
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Feed parses the data and calls fn for every complete event. The events are
+// one of PrintEvent, ControlEvent, ESCEvent, CSIEvent, OSCEvent or DCSEvent.
+func (p *Parser) Feed(data []byte, fn func(event any)) {
+	for _, b := range data {
+		p.advance(b, fn)
+	}
+}
+
+func (p *Parser) advance(b byte, fn func(event any)) {
+	// Transitions that apply in every state.
+	switch b {
+	case 0x18, 0x1a: // CAN, SUB
+		// A string that is cancelled is dropped, only ST or BEL complete it.
+		p.utf8Len = 0
+		p.data = p.data[:0]
+		fn(ControlEvent{Code: b})
+		p.state = stateGround
+		return
+	case 0x1b: // ESC
+		p.utf8Len = 0
+		p.exitString(fn)
+		p.clear()
+		p.state = stateEscape
+		return
+	}
+
+	switch p.state {
+	case stateGround:
+		p.ground(b, fn)
+	case stateEscape:
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+			p.state = stateEscapeIntermediate
+		case b == '[':
+			p.clear()
+			p.state = stateCSIEntry
+		case b == ']':
+			p.data = p.data[:0]
+			p.state = stateOSCString
+		case b == 'P':
+			p.clear()
+			p.state = stateDCSEntry
+		case b == 'X' || b == '^' || b == '_':
+			p.state = stateSOSPMAPCString
+		case b >= 0x30 && b <= 0x7e:
+			p.dispatchESC(b, fn)
+			p.state = stateGround
+		}
+	case stateEscapeIntermediate:
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+		case b >= 0x30 && b <= 0x7e:
+			p.dispatchESC(b, fn)
+			p.state = stateGround
+		}
+	case stateCSIEntry:
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+			p.state = stateCSIIntermediate
+		case isParam(b):
+			p.param(b)
+			p.state = stateCSIParam
+		case b >= 0x3c && b <= 0x3f:
+			p.prefix = b
+			p.state = stateCSIParam
+		case b >= 0x40 && b <= 0x7e:
+			p.dispatchCSI(b, fn)
+			p.state = stateGround
+		}
+	case stateCSIParam:
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case isParam(b):
+			p.param(b)
+		case b >= 0x3c && b <= 0x3f:
+			p.state = stateCSIIgnore
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+			p.state = stateCSIIntermediate
+		case b >= 0x40 && b <= 0x7e:
+			p.dispatchCSI(b, fn)
+			p.state = stateGround
+		}
+	case stateCSIIntermediate:
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+		case b >= 0x30 && b <= 0x3f:
+			p.state = stateCSIIgnore
+		case b >= 0x40 && b <= 0x7e:
+			p.dispatchCSI(b, fn)
+			p.state = stateGround
+		}
+	case stateCSIIgnore:
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case b >= 0x40 && b <= 0x7e:
+			p.state = stateGround
+		}
+	case stateDCSEntry:
+		switch {
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+			p.state = stateDCSIntermediate
+		case isParam(b):
+			p.param(b)
+			p.state = stateDCSParam
+		case b >= 0x3c && b <= 0x3f:
+			p.prefix = b
+			p.state = stateDCSParam
+		case b >= 0x40 && b <= 0x7e:
+			p.hook(b)
+		}
+	case stateDCSParam:
+		switch {
+		case isParam(b):
+			p.param(b)
+		case b >= 0x3c && b <= 0x3f:
+			p.state = stateDCSIgnore
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+			p.state = stateDCSIntermediate
+		case b >= 0x40 && b <= 0x7e:
+			p.hook(b)
+		}
+	case stateDCSIntermediate:
+		switch {
+		case b >= 0x20 && b <= 0x2f:
+			p.collect(b)
+		case b >= 0x30 && b <= 0x3f:
+			p.state = stateDCSIgnore
+		case b >= 0x40 && b <= 0x7e:
+			p.hook(b)
+		}
+	case stateDCSPassthrough:
+		if b != 0x7f {
+			p.put(b)
+		}
+	case stateOSCString:
+		switch {
+		case b == 0x07: // BEL terminates the string like ST.
+			p.exitString(fn)
+			p.state = stateGround
+		case b >= 0x20:
+			p.put(b)
+		}
+	case stateDCSIgnore, stateSOSPMAPCString:
+		// Everything is ignored until the string is terminated.
+	}
+}
+
+// ground handles printable characters and decodes multibyte UTF-8 characters.
+func (p *Parser) ground(b byte, fn func(event any)) {
+	if b < 0x80 {
+		// A pending multibyte character is invalid if it is interrupted.
+		p.utf8Len = 0
+
+		switch {
+		case isC0(b):
+			fn(ControlEvent{Code: b})
+		case b != 0x7f:
+			fn(PrintEvent{Rune: rune(b)})
+		}
+		return
+	}
+
+	isContinuation := b&0xc0 == 0x80
+	if p.utf8Len == 0 && isContinuation {
+		// Stray continuation byte.
+		return
+	}
+	if p.utf8Len > 0 && !isContinuation {
+		// Start of a new character before the last one was complete.
+		p.utf8Len = 0
+	}
+
+	p.utf8[p.utf8Len] = b
+	p.utf8Len++
+
+	if !utf8.FullRune(p.utf8[:p.utf8Len]) {
+		if p.utf8Len == len(p.utf8) {
+			p.utf8Len = 0
+		}
+		return
+	}
+
+	r, size := utf8.DecodeRune(p.utf8[:p.utf8Len])
+	p.utf8Len = 0
+	if r == utf8.RuneError && size <= 1 {
+		return
+	}
+
+	fn(PrintEvent{Rune: r})
+}
+
+// clear resets the collected parameters and intermediates.
+func (p *Parser) clear() {
+	p.prefix = 0
+	p.params = p.params[:0]
+	p.intermediates = p.intermediates[:0]
+	p.ignore = false
+}
+
+func (p *Parser) collect(b byte) {
+	if len(p.intermediates) >= maxIntermediates {
+		p.ignore = true
+		return
+	}
+	p.intermediates = append(p.intermediates, b)
+}
+
+func (p *Parser) param(b byte) {
+	if len(p.params) >= maxParamsLength {
+		p.ignore = true
+		return
+	}
+	p.params = append(p.params, b)
+}
+
+func (p *Parser) put(b byte) {
+	if len(p.data) < maxStringLength {
+		p.data = append(p.data, b)
+	}
+}
+
+// hook starts the data part of a device control string.
+func (p *Parser) hook(final byte) {
+	p.data = append(p.data[:0], final)
+	p.state = stateDCSPassthrough
+}
+
+// exitString emits the OSC or DCS string that is terminated by leaving the current state.
+func (p *Parser) exitString(fn func(event any)) {
+	switch p.state {
+	case stateOSCString:
+		fn(OSCEvent{Data: string(p.data)})
+	case stateDCSPassthrough:
+		if !p.ignore {
+			fn(DCSEvent{
+				Prefix:        p.prefix,
+				Params:        string(p.params),
+				Intermediates: string(p.intermediates),
+				Final:         p.data[0],
+				Data:          string(p.data[1:]),
+			})
+		}
+	}
+}
+
+func (p *Parser) dispatchESC(final byte, fn func(event any)) {
+	if p.ignore {
+		return
+	}
+
+	fn(ESCEvent{
+		Intermediates: string(p.intermediates),
+		Final:         final,
+	})
+}
+
+func (p *Parser) dispatchCSI(final byte, fn func(event any)) {
+	if p.ignore {
+		return
+	}
+
+	fn(CSIEvent{
+		Prefix:        p.prefix,
+		Params:        string(p.params),
+		Intermediates: string(p.intermediates),
+		Final:         final,
+	})
+}
+
+// isC0 returns true for the C0 control characters that are executed in most states.
+func isC0(b byte) bool {
+	return b <= 0x17 || b == 0x19 || (b >= 0x1c && b <= 0x1f)
+}
+
+// isParam returns true for parameter bytes. The colon is accepted for sub parameters (e.g. 38:2::255:0:0).
+func isParam(b byte) bool {
+	return (b >= '0' && b <= '9') || b == ';' || b == ':'
+}
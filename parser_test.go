@@ -0,0 +1,156 @@
+package crt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// parseEvents feeds the chunks to a new parser and returns all emitted events.
+func parseEvents(chunks ...string) []any {
+	var events []any
+	p := NewParser()
+	for i := range chunks {
+		p.Feed([]byte(chunks[i]), func(event any) {
+			events = append(events, event)
+		})
+	}
+	return events
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []any
+	}{
+		{
+			name:  "print",
+			input: "aü€😀",
+			want:  []any{PrintEvent{'a'}, PrintEvent{'ü'}, PrintEvent{'€'}, PrintEvent{'😀'}},
+		},
+		{
+			name:  "control",
+			input: "a\r\n\t\x07",
+			want:  []any{PrintEvent{'a'}, ControlEvent{'\r'}, ControlEvent{'\n'}, ControlEvent{'\t'}, ControlEvent{0x07}},
+		},
+		{
+			name:  "csi",
+			input: "\x1b[1;2H\x1b[?1049h\x1b[>c\x1b[ q\x1b[m",
+			want: []any{
+				CSIEvent{Params: "1;2", Final: 'H'},
+				CSIEvent{Prefix: '?', Params: "1049", Final: 'h'},
+				CSIEvent{Prefix: '>', Final: 'c'},
+				CSIEvent{Intermediates: " ", Final: 'q'},
+				CSIEvent{Final: 'm'},
+			},
+		},
+		{
+			name:  "csi with sub parameters",
+			input: "\x1b[38:2::255:0:0m",
+			want:  []any{CSIEvent{Params: "38:2::255:0:0", Final: 'm'}},
+		},
+		{
+			name:  "control inside csi",
+			input: "\x1b[1\n;2H",
+			want:  []any{ControlEvent{'\n'}, CSIEvent{Params: "1;2", Final: 'H'}},
+		},
+		{
+			name:  "invalid csi is ignored",
+			input: "\x1b[1?2Ha",
+			want:  []any{PrintEvent{'a'}},
+		},
+		{
+			name:  "esc",
+			input: "\x1b7\x1b8\x1b(B",
+			want:  []any{ESCEvent{Final: '7'}, ESCEvent{Final: '8'}, ESCEvent{Intermediates: "(", Final: 'B'}},
+		},
+		{
+			name:  "osc terminated by bel",
+			input: "\x1b]0;hello wörld\x07a",
+			want:  []any{OSCEvent{Data: "0;hello wörld"}, PrintEvent{'a'}},
+		},
+		{
+			name:  "osc terminated by st",
+			input: "\x1b]2;title\x1b\\a",
+			want:  []any{OSCEvent{Data: "2;title"}, ESCEvent{Final: '\\'}, PrintEvent{'a'}},
+		},
+		{
+			name:  "dcs",
+			input: "\x1bP1$qm\x1b\\",
+			want:  []any{DCSEvent{Params: "1", Intermediates: "$", Final: 'q', Data: "m"}, ESCEvent{Final: '\\'}},
+		},
+		{
+			name:  "apc is ignored",
+			input: "\x1b_ignored\x1b\\a",
+			want:  []any{ESCEvent{Final: '\\'}, PrintEvent{'a'}},
+		},
+		{
+			name:  "cancel",
+			input: "\x1b[12\x18a",
+			want:  []any{ControlEvent{0x18}, PrintEvent{'a'}},
+		},
+		{
+			name:  "cancelled osc",
+			input: "\x1b]52;c;aGVsbG8=\x18a\x1b]0;title\x1aa",
+			want:  []any{ControlEvent{0x18}, PrintEvent{'a'}, ControlEvent{0x1a}, PrintEvent{'a'}},
+		},
+		{
+			name:  "cancelled dcs",
+			input: "\x1bP1$qm\x18a",
+			want:  []any{ControlEvent{0x18}, PrintEvent{'a'}},
+		},
+		{
+			name:  "invalid utf8 is dropped",
+			input: "a\x80b\xe2\x82c",
+			want:  []any{PrintEvent{'a'}, PrintEvent{'b'}, PrintEvent{'c'}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, parseEvents(test.input))
+		})
+	}
+}
+
+func TestParserSplitInput(t *testing.T) {
+	// Sequences and characters that are split between two feeds are completed by the next one.
+	assert.Equal(t, []any{
+		CSIEvent{Params: "38;2;255;0;0", Final: 'm'},
+		PrintEvent{'€'},
+		OSCEvent{Data: "0;title"},
+	}, parseEvents("\x1b[38;2;2", "55;0;0m\xe2\x82", "\xac\x1b]0;ti", "tle\x07"))
+
+	assert.Equal(t, []any{ESCEvent{Final: '7'}}, parseEvents("\x1b", "7"))
+}
+
+func TestWindowSplitInput(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.feed([]byte("a\x1b[1"))
+	win.feed([]byte("mb\x1b["))
+	win.feed([]byte("2;3Hc"))
+
+	assert.Equal(t, []string{"ab", "  c"}, screenLines(win))
+	assert.Equal(t, FontWeightBold, win.grid[0][1].Weight)
+}
+
+func FuzzParserChunks(f *testing.F) {
+	f.Add([]byte("hello \x1b[1;31mworld\x1b[0m\r\n"), byte(3))
+	f.Add([]byte("\x1b]0;title\x07\x1b[?1049h\x1bP$qm\x1b\\"), byte(1))
+	f.Add([]byte("ü€😀\x1b7\x1b8\x1b[38:2::1:2:3m"), byte(2))
+
+	f.Fuzz(func(t *testing.T, data []byte, chunkSize byte) {
+		size := int(chunkSize%16) + 1
+
+		var chunks []string
+		for i := 0; i < len(data); i += size {
+			end := i + size
+			if end > len(data) {
+				end = len(data)
+			}
+			chunks = append(chunks, string(data[i:end]))
+		}
+
+		assert.Equal(t, parseEvents(string(data)), parseEvents(chunks...))
+	})
+}
@@ -6,6 +6,9 @@ import "io"
 type ConcurrentRW struct {
 	input  chan []byte
 	output chan []byte
+
+	// Data of the last chunk that didn't fit into the buffer of Read.
+	unread []byte
 }
 
 // NewConcurrentRW creates a new concurrent read/write buffer.
@@ -24,13 +27,19 @@ func (rw *ConcurrentRW) Write(p []byte) (n int, err error) {
 	return len(data), nil
 }
 
-// Read reads data from the buffer.
+// Read reads data from the buffer. If p is smaller than the available data,
+// the rest is returned by the next calls.
 func (rw *ConcurrentRW) Read(p []byte) (n int, err error) {
-	data, ok := <-rw.output
-	if !ok {
-		return 0, io.EOF
+	if len(rw.unread) == 0 {
+		data, ok := <-rw.output
+		if !ok {
+			return 0, io.EOF
+		}
+		rw.unread = data
 	}
-	n = copy(p, data)
+
+	n = copy(p, rw.unread)
+	rw.unread = rw.unread[n:]
 	return n, nil
 }
 
@@ -0,0 +1,31 @@
+package crt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestConcurrentRWShortReads(t *testing.T) {
+	rw := NewConcurrentRW()
+	go rw.Run()
+
+	// Like bubbletea the data is read with a small buffer, so nothing may be lost.
+	reply := ESC + "]52;c;" + strings.Repeat("QUJD", 300) + ESC + "\\"
+	_, _ = rw.Write([]byte(reply))
+	_, _ = rw.Write([]byte(ESC + "[0n"))
+
+	var read []byte
+	buf := make([]byte, 256)
+	for len(read) < len(reply)+len(ESC+"[0n") {
+		n, err := rw.Read(buf)
+		assert.NoError(t, err)
+		read = append(read, buf[:n]...)
+	}
+	assert.Equal(t, reply+ESC+"[0n", string(read))
+
+	close(rw.input)
+	_, err := rw.Read(buf)
+	assert.Equal(t, io.EOF, err)
+}
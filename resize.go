@@ -0,0 +1,285 @@
+package crt
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"image"
+)
+
+// WithResizing sets the resizing mode of the window. If the window can be resized
+// the grid follows the size of the window.
+func WithResizing(mode ebiten.WindowResizingModeType) WindowOption {
+	return func(window *Window) {
+		ebiten.SetWindowResizingMode(mode)
+	}
+}
+
+// WithReflow enables or disables the reflow of wrapped lines on resize.
+func WithReflow(reflow bool) WindowOption {
+	return func(window *Window) {
+		window.SetReflow(reflow)
+	}
+}
+
+// SetReflow enables or disables the reflow of wrapped lines on resize. If it is enabled, lines that
+// were wrapped because they didn't fit are joined and wrapped again at the new width. Otherwise the
+// lines are cut off or padded. Reflow is enabled by default.
+func (g *Window) SetReflow(reflow bool) {
+	g.noReflow = !reflow
+}
+
+// Resize changes the number of cells of the window. The primary screen and the scrollback are
+// reflowed, the alternate screen is cut off or padded because the application redraws it anyway.
+// The scrolling region is reset and the input adapter is notified about the new size. The adapter
+// might wait for the application, so the window must not be locked by the caller.
+func (g *Window) Resize(cellsWidth, cellsHeight int) {
+	if g.resize(cellsWidth, cellsHeight) {
+		g.notifyWindowSize()
+	}
+}
+
+// resizeToFit changes the number of cells to fit the window size in pixels. The adapter is
+// notified after the window is unlocked, because the application might have to wait for
+// the tty reader, which needs the lock, before it takes the new size.
+func (g *Window) resizeToFit(width, height int) {
+	g.Lock()
+	g.windowWidth, g.windowHeight = width, height
+	resized := g.resize(width/g.cellWidth, height/g.cellHeight)
+	g.Unlock()
+
+	if resized {
+		g.notifyWindowSize()
+	}
+}
+
+// notifyWindowSize sends the number of cells to the input adapter.
+func (g *Window) notifyWindowSize() {
+	g.inputAdapter.HandleWindowSize(WindowSize{
+		Width:  g.cellsWidth,
+		Height: g.cellsHeight,
+	})
+}
+
+// resize changes the number of cells and returns true if it changed.
+func (g *Window) resize(cellsWidth, cellsHeight int) bool {
+	if cellsWidth < 1 || cellsHeight < 1 || (cellsWidth == g.cellsWidth && cellsHeight == g.cellsHeight) {
+		return false
+	}
+
+	g.cellsWidth = cellsWidth
+	g.cellsHeight = cellsHeight
+	g.lastCell = nil
+	g.tabStops = resizeTabStops(g.tabStops, cellsWidth)
+
+	primary := &g.inactiveScreen
+	alternate := &screen{grid: g.grid, cursorX: g.cursorX, cursorY: g.cursorY}
+	if !g.altScreen {
+		primary, alternate = alternate, primary
+	}
+
+	if g.noReflow {
+		// Lines above the cursor are moved to the scrollback, so the cursor stays on the screen.
+		for y := 0; y <= primary.cursorY-cellsHeight; y++ {
+			g.scrollback.push(primary.grid[y])
+		}
+		primary.grid, primary.cursorX, primary.cursorY = g.resizeGrid(primary.grid, primary.cursorX, primary.cursorY, true)
+	} else {
+		primary.grid, primary.cursorX, primary.cursorY = g.reflowGrid(primary.grid, primary.cursorX, primary.cursorY)
+	}
+	if alternate.grid != nil {
+		alternate.grid, alternate.cursorX, alternate.cursorY = g.resizeGrid(alternate.grid, alternate.cursorX, alternate.cursorY, false)
+	}
+
+	if g.altScreen {
+		g.grid, g.cursorX, g.cursorY = alternate.grid, alternate.cursorX, alternate.cursorY
+	} else {
+		g.grid, g.cursorX, g.cursorY = primary.grid, primary.cursorX, primary.cursorY
+	}
+
+	g.scrollTop = 0
+	g.scrollBottom = cellsHeight - 1
+	g.ScrollView(0)
+	g.resizeBuffers()
+	return true
+}
+
+// resizeBuffers re-creates the images to match the number and size of the cells.
+func (g *Window) resizeBuffers() {
+	g.bgColors = image.NewRGBA(image.Rect(0, 0, g.cellsWidth*g.cellWidth, g.cellsHeight*g.cellHeight))
+	g.lastBuffer = ebiten.NewImage(g.cellsWidth*g.cellWidth, g.cellsHeight*g.cellHeight)
+	g.shaderBuffer = nil
+	g.RecalculateBackgrounds()
+}
+
+// emptyCell returns a blank cell with the default colors.
+func emptyCell() GridCell {
+	return GridCell{
+		Char:   ' ',
+		Fg:     DefaultColor,
+		Bg:     DefaultColor,
+		Weight: FontWeightNormal,
+	}
+}
+
+// isEmptyCell returns true if the cell is blank and uses the default background.
+func isEmptyCell(cell GridCell) bool {
+	return cell.Char == ' ' && cell.Bg.IsDefault() && cell.Attributes&^attrWrapline == 0
+}
+
+// fitLine cuts off or pads a line to the width of the window. If the width changes the line no longer wraps.
+func (g *Window) fitLine(line []GridCell) []GridCell {
+	fitted := make([]GridCell, g.cellsWidth)
+	n := copy(fitted, line)
+	for x := n; x < len(fitted); x++ {
+		fitted[x] = emptyCell()
+	}
+	if len(line) != len(fitted) {
+		fitted[len(fitted)-1].Attributes &^= attrWrapline
+	}
+	return fitted
+}
+
+// resizeGrid cuts off or pads the grid to the size of the window. If keepCursor is set, lines
+// above the cursor are dropped so the cursor stays on the screen, otherwise lines at the bottom are dropped.
+func (g *Window) resizeGrid(grid [][]GridCell, cursorX, cursorY int, keepCursor bool) ([][]GridCell, int, int) {
+	if keepCursor && cursorY >= g.cellsHeight {
+		grid = grid[cursorY-g.cellsHeight+1:]
+		cursorY = g.cellsHeight - 1
+	}
+
+	resized := make([][]GridCell, g.cellsHeight)
+	for y := range resized {
+		if y < len(grid) {
+			resized[y] = g.fitLine(grid[y])
+		} else {
+			resized[y] = g.fitLine(nil)
+		}
+	}
+
+	return resized, clamp(cursorX, 0, g.cellsWidth-1), clamp(cursorY, 0, g.cellsHeight-1)
+}
+
+// reflowGrid joins the wrapped lines of the scrollback and the grid and wraps them again at the width
+// of the window. Lines that don't fit on the screen are moved to the scrollback. The cursor stays on
+// the same character.
+func (g *Window) reflowGrid(grid [][]GridCell, cursorX, cursorY int) ([][]GridCell, int, int) {
+	// Join the wrapped lines to logical lines.
+	var lines [][]GridCell
+	cursorLine, cursorCol := 0, 0
+	wrapped := false
+	for i := 0; i < g.scrollback.count+len(grid); i++ {
+		var row []GridCell
+		if i < g.scrollback.count {
+			row = g.scrollback.line(i)
+		} else {
+			row = grid[i-g.scrollback.count]
+		}
+
+		if !wrapped || len(lines) == 0 {
+			lines = append(lines, nil)
+		}
+		last := len(lines) - 1
+
+		if i == g.scrollback.count+cursorY {
+			cursorLine, cursorCol = last, len(lines[last])+cursorX
+		}
+
+		lines[last] = append(lines[last], row...)
+		wrapped = len(row) > 0 && row[len(row)-1].Attributes&attrWrapline != 0
+		if wrapped {
+			end := len(lines[last]) - 1
+			lines[last][end].Attributes &^= attrWrapline
+
+			// The empty cell before a wide character that didn't fit is not part of the text.
+			if lines[last][end].Char == wideSpacer && !g.isWideLead(lines[last], end-1) {
+				lines[last] = lines[last][:end]
+			}
+		}
+	}
+
+	// Drop the trailing blanks, but keep the cells up to the cursor.
+	for i := range lines {
+		n := len(lines[i])
+		for n > 0 && isEmptyCell(lines[i][n-1]) && (i != cursorLine || n > cursorCol) {
+			n--
+		}
+		lines[i] = lines[i][:n]
+	}
+
+	// Wrap the logical lines at the new width.
+	var rows [][]GridCell
+	cursorRow := 0
+	for i, line := range lines {
+		for start := 0; ; {
+			end := start + g.cellsWidth
+			if end >= len(line) {
+				end = len(line)
+			} else if g.isWideLead(line, end-1) && end-1 > start {
+				// Wide characters are not split.
+				end--
+			}
+
+			row := g.fitLine(line[start:end])
+			if end < len(line) {
+				if end-start < g.cellsWidth {
+					row[len(row)-1].Char = wideSpacer
+				}
+				row[len(row)-1].Attributes |= attrWrapline
+			}
+
+			// If the cursor is at the end of a full row it waits to wrap there.
+			if i == cursorLine && cursorCol >= start && (cursorCol < end || end == len(line)) {
+				cursorRow, cursorX = len(rows), cursorCol-start
+			}
+
+			rows = append(rows, row)
+			if end == len(line) {
+				break
+			}
+			start = end
+		}
+	}
+
+	// Drop the empty rows below the cursor, so the content moves to the bottom if the screen shrinks.
+	for len(rows)-1 > cursorRow && isEmptyLine(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	if len(rows) > cursorRow+g.cellsHeight {
+		rows = rows[:cursorRow+g.cellsHeight]
+	}
+
+	// Everything above the screen is kept in the scrollback.
+	g.scrollback.clear()
+	if split := len(rows) - g.cellsHeight; split > 0 {
+		for _, row := range rows[:split] {
+			g.scrollback.push(row)
+		}
+		rows = rows[split:]
+		cursorRow -= split
+	}
+	for len(rows) < g.cellsHeight {
+		rows = append(rows, g.fitLine(nil))
+	}
+
+	return rows, cursorX, cursorRow
+}
+
+// isEmptyLine returns true if all cells of the line are empty.
+func isEmptyLine(line []GridCell) bool {
+	for i := range line {
+		if !isEmptyCell(line[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// clamp limits val to the range between min and max.
+func clamp(val, min, max int) int {
+	if val < min {
+		return min
+	}
+	if val > max {
+		return max
+	}
+	return val
+}
@@ -0,0 +1,141 @@
+package crt
+
+import (
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// sizeAdapter records the window sizes that are sent to the adapter.
+type sizeAdapter struct {
+	EmptyAdapter
+	sizes []WindowSize
+}
+
+func (a *sizeAdapter) HandleWindowSize(size WindowSize) {
+	a.sizes = append(a.sizes, size)
+}
+
+func TestResizeNotifiesAdapter(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	adapter := &sizeAdapter{}
+	win.inputAdapter = adapter
+
+	win.Resize(20, 5)
+	win.Resize(20, 5)
+
+	assert.Equal(t, []WindowSize{{Width: 20, Height: 5}}, adapter.sizes)
+	assert.Equal(t, 20, win.GetCellsWidth())
+	assert.Equal(t, 5, win.GetCellsHeight())
+	assert.Equal(t, 20*win.cellWidth, win.bgColors.Bounds().Dx())
+	assert.Equal(t, 5*win.cellHeight, win.lastBuffer.Bounds().Dy())
+
+	top, bottom := win.GetScrollingRegion()
+	assert.Equal(t, 1, top)
+	assert.Equal(t, 5, bottom)
+}
+
+// lockingAdapter locks the window when the size changes, like bubbletea does indirectly: the
+// program only takes the new size after the tty reader, which needs the lock, read its output.
+type lockingAdapter struct {
+	EmptyAdapter
+	win   *Window
+	sizes []WindowSize
+}
+
+func (a *lockingAdapter) HandleWindowSize(size WindowSize) {
+	a.win.Lock()
+	a.sizes = append(a.sizes, size)
+	a.win.Unlock()
+}
+
+func TestResizeToFitUnlocksBeforeNotifying(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	adapter := &lockingAdapter{win: win}
+	win.inputAdapter = adapter
+
+	done := make(chan struct{})
+	go func() {
+		win.resizeToFit(20*win.cellWidth+3, 5*win.cellHeight+1)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("resize deadlocked")
+	}
+	assert.Equal(t, []WindowSize{{Width: 20, Height: 5}}, adapter.sizes)
+}
+
+func TestResizeReflow(t *testing.T) {
+	win := newTestWindow(t, 6, 3)
+	win.feed([]byte("abcdefgh\nxy"))
+	assert.Equal(t, []string{"abcdef", "gh", "xy"}, screenLines(win))
+
+	// Wider: the wrapped line is joined.
+	win.Resize(10, 3)
+	assert.Equal(t, []string{"abcdefgh", "xy", ""}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+
+	// Narrower: the line is wrapped again and the top moves to the scrollback.
+	win.Resize(3, 3)
+	assert.Equal(t, []string{"abc"}, scrollbackLines(win))
+	assert.Equal(t, []string{"def", "gh", "xy"}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 2, win.cursorY)
+
+	// Printing continues at the cursor.
+	win.feed([]byte("z"))
+	assert.Equal(t, "xyz", screenLines(win)[2])
+
+	// Taller: lines are taken back from the scrollback.
+	win.Resize(10, 4)
+	assert.Equal(t, 0, win.GetScrollbackLen())
+	assert.Equal(t, []string{"abcdefgh", "xyz", "", ""}, screenLines(win))
+	assert.Equal(t, 3, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+}
+
+func TestResizeReflowPendingWrap(t *testing.T) {
+	win := newTestWindow(t, 4, 2)
+	win.feed([]byte("abcd"))
+	assert.Equal(t, 4, win.cursorX)
+
+	win.Resize(2, 3)
+	assert.Equal(t, []string{"ab", "cd", ""}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+
+	win.feed([]byte("e"))
+	assert.Equal(t, []string{"ab", "cd", "e"}, screenLines(win))
+}
+
+func TestResizeWithoutReflow(t *testing.T) {
+	win := newTestWindow(t, 6, 3)
+	win.SetReflow(false)
+	win.feed([]byte("abcdefgh\nxy"))
+
+	win.Resize(4, 2)
+	assert.Equal(t, []string{"abcdef"}, scrollbackLines(win))
+	assert.Equal(t, []string{"gh", "xy"}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+}
+
+func TestResizeAltScreen(t *testing.T) {
+	win := newTestWindow(t, 6, 3)
+	win.feed([]byte("abcdefgh"))
+	win.feed([]byte(termenv.CSI + "?1049h" + termenv.CSI + "3;6H" + "x"))
+
+	win.Resize(4, 2)
+	assert.Equal(t, []string{"", ""}, screenLines(win))
+	assert.Equal(t, 3, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+
+	// The primary screen was reflowed in the background.
+	win.feed([]byte(termenv.CSI + "?1049l"))
+	assert.Equal(t, []string{"abcd", "efgh"}, screenLines(win))
+}
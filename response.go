@@ -0,0 +1,171 @@
+package crt
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// WithResponseWriter sets where the replies to queries of the application are written to.
+func WithResponseWriter(w io.Writer) WindowOption {
+	return func(window *Window) {
+		window.SetResponseWriter(w)
+	}
+}
+
+// SetResponseWriter sets where the replies to queries of the application (e.g. the cursor
+// position or the clipboard) are written to. This is usually the input stream of the application.
+// Without a writer the replies are dropped.
+func (g *Window) SetResponseWriter(w io.Writer) {
+	g.responses.mtx.Lock()
+	defer g.responses.mtx.Unlock()
+	g.responses.writer = w
+}
+
+// respond queues a reply to the application.
+func (g *Window) respond(s string) {
+	g.responses.push(func() string {
+		return s
+	})
+}
+
+// responseQueue runs jobs that reply to the application on its own goroutine, so neither an
+// application that is slow to read its input nor a slow clipboard blocks the terminal. The
+// replies are written in the order of the queries.
+type responseQueue struct {
+	mtx     sync.Mutex
+	writer  io.Writer
+	jobs    []func() string
+	running bool
+	pending sync.WaitGroup
+}
+
+// push queues a job. The string it returns is written to the application.
+func (q *responseQueue) push(job func() string) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	q.pending.Add(1)
+	q.jobs = append(q.jobs, job)
+	if !q.running {
+		q.running = true
+		go q.run()
+	}
+}
+
+// run works off the queued jobs until the queue is empty.
+func (q *responseQueue) run() {
+	for {
+		q.mtx.Lock()
+		if len(q.jobs) == 0 {
+			q.running = false
+			q.mtx.Unlock()
+			return
+		}
+		job := q.jobs[0]
+		q.jobs = q.jobs[1:]
+		q.mtx.Unlock()
+
+		if reply := job(); reply != "" {
+			q.mtx.Lock()
+			w := q.writer
+			q.mtx.Unlock()
+
+			if w != nil {
+				_, _ = io.WriteString(w, reply)
+			}
+		}
+		q.pending.Done()
+	}
+}
+
+// wait blocks until all queued jobs are done.
+func (q *responseQueue) wait() {
+	q.pending.Wait()
+}
+
+const (
+	// DefaultIdentity is the name the terminal reports to XTVERSION queries.
+	DefaultIdentity = "crt"
+
+	// primaryDeviceAttributes reports a VT220 (62) with ANSI colors (22). No other extensions are supported.
+	primaryDeviceAttributes = ESC + "[?62;22c"
+
+	// secondaryDeviceAttributes reports a VT220 (1) without a firmware version.
+	secondaryDeviceAttributes = ESC + "[>1;0;0c"
+)
+
+// Mode states of the DECRQM replies.
+const (
+	modeNotRecognized = 0
+	modeSet           = 1
+	modeReset         = 2
+)
+
+// WithIdentity sets the name the terminal reports to XTVERSION queries.
+func WithIdentity(identity string) WindowOption {
+	return func(window *Window) {
+		window.SetIdentity(identity)
+	}
+}
+
+// SetIdentity sets the name the terminal reports to XTVERSION queries (CSI > q). Applications
+// use it to detect the terminal, so it should include the name of the host (e.g. "mygame(1.2)").
+func (g *Window) SetIdentity(identity string) {
+	g.identity = identity
+}
+
+// reportStatus answers the device status reports DSR 5 (status) and DSR 6 (cursor position).
+func (g *Window) reportStatus(t int, private bool) {
+	switch t {
+	case 5:
+		g.respond(ESC + "[0n")
+	case 6:
+		y := g.cursorY
+		if g.originMode {
+			y -= g.scrollTop
+		}
+
+		prefix := ""
+		if private {
+			prefix = "?"
+		}
+		g.respond(fmt.Sprintf("%s[%s%d;%dR", ESC, prefix, y+1, g.clampedCursorX()+1))
+	}
+}
+
+// reportMode answers DECRQM with the state of an ANSI or DEC private mode.
+func (g *Window) reportMode(mode int, private bool) {
+	state := modeNotRecognized
+	if private {
+		switch mode {
+		case 6:
+			state = boolModeState(g.originMode)
+		case 7:
+			state = boolModeState(g.autowrap)
+		case 25:
+			state = boolModeState(g.showCursor)
+		case 47, 1047, 1049:
+			state = boolModeState(g.altScreen)
+		case 9, 1000, 1002, 1003:
+			state = boolModeState(g.mouseMode == mouseModes[mode])
+		case 1006:
+			state = boolModeState(g.mouseSGR)
+		}
+		g.respond(fmt.Sprintf("%s[?%d;%d$y", ESC, mode, state))
+		return
+	}
+
+	if mode == 20 {
+		state = boolModeState(g.newLineMode)
+	}
+	g.respond(fmt.Sprintf("%s[%d;%d$y", ESC, mode, state))
+}
+
+// boolModeState returns the DECRQM state of a mode that is either set or reset.
+func boolModeState(val bool) int {
+	if val {
+		return modeSet
+	}
+	return modeReset
+}
@@ -0,0 +1,84 @@
+package crt
+
+import (
+	"bytes"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestQueryResponses(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	var response bytes.Buffer
+	win.SetResponseWriter(&response)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{ESC + "[5n", ESC + "[0n"},
+		{ESC + "[3;4H" + ESC + "[6n", ESC + "[3;4R"},
+		{ESC + "[?6n", ESC + "[?3;4R"},
+		{ESC + "[c", ESC + "[?62;22c"},
+		{ESC + "[>c", ESC + "[>1;0;0c"},
+		{ESC + "[>q", ESC + "P>|crt" + ESC + "\\"},
+		{ESC + "[20$p", ESC + "[20;1$y"},
+		{ESC + "[4$p", ESC + "[4;0$y"},
+		{ESC + "[?25$p", ESC + "[?25;2$y"},
+		{ESC + "[?7$p", ESC + "[?7;1$y"},
+		{ESC + "[?7l" + ESC + "[?7$p", ESC + "[?7;2$y"},
+		{ESC + "[?1049h" + ESC + "[?1049$p", ESC + "[?1049;1$y"},
+		{ESC + "[?2004$p", ESC + "[?2004;0$y"},
+	}
+
+	for _, test := range tests {
+		response.Reset()
+		win.feed([]byte(test.query))
+		win.responses.wait()
+		assert.Equal(t, test.want, response.String(), test.query)
+	}
+}
+
+func TestQueryResponsesOriginMode(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	var response bytes.Buffer
+	win.SetResponseWriter(&response)
+
+	// The cursor waits to wrap at the end of the line but is reported in the last column.
+	win.feed([]byte(ESC + "[2;4r" + ESC + "[?6h" + ESC + "[2;1H0123456789" + ESC + "[6n"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"[2;10R", response.String())
+}
+
+func TestIdentity(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	var response bytes.Buffer
+	win.SetResponseWriter(&response)
+	win.SetIdentity("game(1.0)")
+
+	win.feed([]byte(ESC + "[>0q"))
+	win.responses.wait()
+	assert.Equal(t, ESC+"P>|game(1.0)"+ESC+"\\", response.String())
+
+	// Without a writer the replies are dropped.
+	win.SetResponseWriter(nil)
+	win.feed([]byte(ESC + "[c"))
+}
+
+func TestResponsesDontBlock(t *testing.T) {
+	win := newTestWindow(t, 10, 5)
+	r, w := io.Pipe()
+	win.SetResponseWriter(w)
+
+	// Nobody reads the replies yet, so they are queued in order.
+	for i := 0; i < 20; i++ {
+		win.feed([]byte(ESC + "[5n"))
+	}
+	win.feed([]byte(ESC + "[c"))
+
+	reply := make([]byte, 20*len(ESC+"[0n")+len(primaryDeviceAttributes))
+	_, err := io.ReadFull(r, reply)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Repeat(ESC+"[0n", 20)+primaryDeviceAttributes, string(reply))
+}
@@ -0,0 +1,87 @@
+package crt
+
+// screen is the state that is kept separately for the primary and the alternate screen buffer.
+type screen struct {
+	grid        [][]GridCell
+	cursorX     int
+	cursorY     int
+	savedCursor *savedCursor
+}
+
+// IsAltScreen returns true if the alternate screen buffer is active.
+func (g *Window) IsAltScreen() bool {
+	return g.altScreen
+}
+
+// enterAltScreen switches to the alternate screen buffer. The cursor keeps its position.
+// If clear is set the alternate screen is erased before it is shown.
+func (g *Window) enterAltScreen(clear bool) {
+	if g.altScreen {
+		return
+	}
+
+	if clear || g.inactiveScreen.grid == nil {
+		g.inactiveScreen.grid = g.newGrid()
+	}
+
+	g.ScrollViewToBottom()
+
+	cursorX, cursorY := g.cursorX, g.cursorY
+	g.swapScreen()
+	g.cursorX, g.cursorY = cursorX, cursorY
+}
+
+// leaveAltScreen switches back to the primary screen buffer and restores its cursor.
+// If clear is set the alternate screen is erased before switching.
+func (g *Window) leaveAltScreen(clear bool) {
+	if !g.altScreen {
+		return
+	}
+
+	if clear {
+		g.grid = g.newGrid()
+	}
+
+	g.swapScreen()
+}
+
+// swapScreen exchanges the active and the inactive screen buffer.
+func (g *Window) swapScreen() {
+	active := screen{
+		grid:        g.grid,
+		cursorX:     g.cursorX,
+		cursorY:     g.cursorY,
+		savedCursor: g.savedCursor,
+	}
+
+	g.grid = g.inactiveScreen.grid
+	g.cursorX = g.inactiveScreen.cursorX
+	g.cursorY = g.inactiveScreen.cursorY
+	g.savedCursor = g.inactiveScreen.savedCursor
+	g.inactiveScreen = active
+	g.altScreen = !g.altScreen
+
+	g.RecalculateBackgrounds()
+}
+
+// forEachLine calls fn for every line of both screens and the scrollback.
+func (g *Window) forEachLine(fn func(line []GridCell)) {
+	for _, line := range g.grid {
+		fn(line)
+	}
+	for _, line := range g.inactiveScreen.grid {
+		fn(line)
+	}
+	for i := 0; i < g.scrollback.count; i++ {
+		fn(g.scrollback.line(i))
+	}
+}
+
+// newGrid creates a grid of blank lines.
+func (g *Window) newGrid() [][]GridCell {
+	grid := make([][]GridCell, g.cellsHeight)
+	for y := range grid {
+		grid[y] = g.newLine()
+	}
+	return grid
+}
@@ -0,0 +1,69 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAltScreen1049(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	win.feed([]byte("shell\n$ "))
+
+	win.feed([]byte(termenv.CSI + termenv.AltScreenSeq))
+	assert.True(t, win.IsAltScreen())
+	assert.Equal(t, []string{"", "", ""}, screenLines(win))
+
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 3, 1) + termenv.CSI + "1mAPP"))
+	assert.Equal(t, []string{"", "", "APP"}, screenLines(win))
+
+	win.feed([]byte(termenv.CSI + termenv.ExitAltScreenSeq))
+	assert.False(t, win.IsAltScreen())
+	assert.Equal(t, []string{"shell", "$", ""}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+	assert.Equal(t, FontWeightNormal, win.curWeight)
+
+	// Entering again shows a cleared screen.
+	win.feed([]byte(termenv.CSI + termenv.AltScreenSeq))
+	assert.Equal(t, []string{"", "", ""}, screenLines(win))
+}
+
+func TestAltScreen47(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	win.feed([]byte("primary"))
+
+	win.feed([]byte(termenv.CSI + "?47h"))
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 2, 1) + "alt"))
+	win.feed([]byte(termenv.CSI + "?47l"))
+	assert.Equal(t, []string{"primary", "", ""}, screenLines(win))
+	assert.Equal(t, 7, win.cursorX)
+	assert.Equal(t, 0, win.cursorY)
+
+	// Mode 47 keeps the content of the alternate screen.
+	win.feed([]byte(termenv.CSI + "?47h"))
+	assert.Equal(t, []string{"", "alt", ""}, screenLines(win))
+
+	// Mode 1047 clears it when leaving.
+	win.feed([]byte(termenv.CSI + "?1047l"))
+	win.feed([]byte(termenv.CSI + "?1047h"))
+	assert.Equal(t, []string{"", "", ""}, screenLines(win))
+}
+
+func TestAltScreenBackgrounds(t *testing.T) {
+	win := newTestWindow(t, 4, 2)
+	win.feed([]byte(termenv.CSI + "48;2;255;10;10mX" + termenv.CSI + "0m"))
+
+	red := func() uint32 {
+		r, _, _, _ := win.bgColors.At(0, 0).RGBA()
+		return r
+	}
+	assert.Equal(t, uint32(0xffff), red())
+
+	win.feed([]byte(termenv.CSI + termenv.AltScreenSeq))
+	assert.Equal(t, uint32(0), red())
+
+	win.feed([]byte(termenv.CSI + termenv.ExitAltScreenSeq))
+	assert.Equal(t, uint32(0xffff), red())
+}
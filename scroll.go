@@ -0,0 +1,109 @@
+package crt
+
+// SetScrollingRegion sets the top and bottom margins of the scrolling region like DECSTBM.
+// The values are one based and inclusive. A value of zero selects the default, so
+// SetScrollingRegion(0, 0) resets the region to the full screen. Invalid regions are ignored.
+// The cursor is moved to the home position afterwards.
+func (g *Window) SetScrollingRegion(top, bottom int) {
+	if top < 1 {
+		top = 1
+	}
+	if bottom < 1 || bottom > g.cellsHeight {
+		bottom = g.cellsHeight
+	}
+	if top >= bottom {
+		return
+	}
+
+	g.scrollTop = top - 1
+	g.scrollBottom = bottom - 1
+	g.cursorHome()
+}
+
+// GetScrollingRegion returns the one based, inclusive top and bottom margins of the scrolling region.
+func (g *Window) GetScrollingRegion() (int, int) {
+	return g.scrollTop + 1, g.scrollBottom + 1
+}
+
+// cursorHome moves the cursor to the home position, which is the top margin if origin mode is enabled.
+func (g *Window) cursorHome() {
+	g.cursorX = 0
+	g.cursorY = 0
+	if g.originMode {
+		g.cursorY = g.scrollTop
+	}
+}
+
+// lineFeed moves the cursor one line down. If the cursor is on the bottom margin
+// the scrolling region is scrolled up instead. Below the region the cursor stops at the last line.
+func (g *Window) lineFeed() {
+	switch {
+	case g.cursorY == g.scrollBottom:
+		g.saveScrollback(g.scrollTop, 1)
+		g.scrollUp(g.scrollTop, g.scrollBottom, 1)
+	case g.cursorY < g.cellsHeight-1:
+		g.cursorY++
+	}
+}
+
+// scrollUp moves the lines between top and bottom (inclusive) up by n lines.
+// The lines that are moved out of the region are discarded and blank lines are inserted at the bottom.
+func (g *Window) scrollUp(top, bottom, n int) {
+	if n <= 0 {
+		return
+	}
+	if n > bottom-top+1 {
+		n = bottom - top + 1
+	}
+
+	copy(g.grid[top:bottom+1], g.grid[top+n:bottom+1])
+	for y := bottom - n + 1; y <= bottom; y++ {
+		g.grid[y] = g.newLine()
+	}
+
+	g.recalculateBackgroundLines(top, bottom)
+}
+
+// scrollDown moves the lines between top and bottom (inclusive) down by n lines.
+// The lines that are moved out of the region are discarded and blank lines are inserted at the top.
+func (g *Window) scrollDown(top, bottom, n int) {
+	if n <= 0 {
+		return
+	}
+	if n > bottom-top+1 {
+		n = bottom - top + 1
+	}
+
+	copy(g.grid[top+n:bottom+1], g.grid[top:bottom+1-n])
+	for y := top; y < top+n; y++ {
+		g.grid[y] = g.newLine()
+	}
+
+	g.recalculateBackgroundLines(top, bottom)
+}
+
+// blankCell returns an empty cell that uses the current background color.
+func (g *Window) blankCell() GridCell {
+	return GridCell{
+		Char:   ' ',
+		Fg:     DefaultColor,
+		Bg:     g.curBg,
+		Weight: FontWeightNormal,
+	}
+}
+
+// newLine creates a blank line that uses the current background color.
+func (g *Window) newLine() []GridCell {
+	line := make([]GridCell, g.cellsWidth)
+	for i := range line {
+		line[i] = g.blankCell()
+	}
+	return line
+}
+
+// recalculateBackgroundLines syncs the background pixels of the lines between top and bottom (inclusive).
+func (g *Window) recalculateBackgroundLines(top, bottom int) {
+	for y := top; y <= bottom; y++ {
+		g.recalculateBackgroundCells(y, 0, g.cellsWidth)
+	}
+}
@@ -0,0 +1,205 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/text"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+	"image"
+)
+
+const (
+	// DefaultScrollbackSize is the number of lines that are kept in the scrollback by default.
+	DefaultScrollbackSize = 1000
+
+	// wheelLines is the number of lines the view is scrolled per step of the mouse wheel.
+	wheelLines = 3
+)
+
+// scrollback is a bounded ring buffer of the lines that were scrolled off the top of the primary screen.
+type scrollback struct {
+	lines [][]GridCell
+	start int
+	count int
+}
+
+func newScrollback(size int) *scrollback {
+	if size < 0 {
+		size = 0
+	}
+	return &scrollback{lines: make([][]GridCell, size)}
+}
+
+// push appends a line. If the buffer is full the oldest line is dropped.
+func (s *scrollback) push(line []GridCell) {
+	if len(s.lines) == 0 {
+		return
+	}
+
+	if s.count < len(s.lines) {
+		s.lines[(s.start+s.count)%len(s.lines)] = line
+		s.count++
+		return
+	}
+
+	s.lines[s.start] = line
+	s.start = (s.start + 1) % len(s.lines)
+}
+
+// line returns the i-th line, where 0 is the oldest line.
+func (s *scrollback) line(i int) []GridCell {
+	return s.lines[(s.start+i)%len(s.lines)]
+}
+
+// clear drops all lines.
+func (s *scrollback) clear() {
+	for i := range s.lines {
+		s.lines[i] = nil
+	}
+	s.start = 0
+	s.count = 0
+}
+
+// resize changes the capacity of the buffer. If it shrinks the newest lines are kept.
+func (s *scrollback) resize(size int) {
+	resized := newScrollback(size)
+	for i := 0; i < s.count; i++ {
+		resized.push(s.line(i))
+	}
+	*s = *resized
+}
+
+// WithScrollbackSize sets the number of lines that are kept in the scrollback.
+func WithScrollbackSize(lines int) WindowOption {
+	return func(window *Window) {
+		window.SetScrollbackSize(lines)
+	}
+}
+
+// SetScrollbackSize sets the number of lines that are kept in the scrollback. A size of zero disables the scrollback.
+func (g *Window) SetScrollbackSize(lines int) {
+	g.scrollback.resize(lines)
+	g.ScrollView(0)
+}
+
+// GetScrollbackLen returns the number of lines that are currently in the scrollback.
+func (g *Window) GetScrollbackLen() int {
+	return g.scrollback.count
+}
+
+// ScrollView moves the view by the given number of lines. Positive values move back into the
+// scrollback, negative values towards the live screen. The offset is clamped to the scrollback.
+func (g *Window) ScrollView(lines int) {
+	offset := g.viewOffset + lines
+	if offset > g.scrollback.count {
+		offset = g.scrollback.count
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	if offset != g.viewOffset {
+		g.viewOffset = offset
+		g.InvalidateBuffer()
+	}
+}
+
+// ScrollViewToBottom moves the view back to the live screen.
+func (g *Window) ScrollViewToBottom() {
+	g.ScrollView(-g.viewOffset)
+}
+
+// GetViewOffset returns how many lines the view is scrolled back into the scrollback.
+func (g *Window) GetViewOffset() int {
+	return g.viewOffset
+}
+
+// saveScrollback moves the first n lines of the screen to the scrollback before they are scrolled
+// off. Only lines that leave the top of the primary screen are kept. If the view is scrolled back
+// it stays on the same content.
+func (g *Window) saveScrollback(top, n int) {
+	if top != 0 || g.altScreen {
+		return
+	}
+	if n > g.cellsHeight {
+		n = g.cellsHeight
+	}
+
+	for y := 0; y < n; y++ {
+		g.scrollback.push(g.grid[y])
+	}
+
+	if g.viewOffset > 0 {
+		g.ScrollView(n)
+	}
+}
+
+// clearScrollback drops all lines of the scrollback and shows the live screen.
+func (g *Window) clearScrollback() {
+	g.scrollback.clear()
+	g.ScrollViewToBottom()
+}
+
+// viewLine returns the line that is shown in the given row of the view.
+func (g *Window) viewLine(y int) []GridCell {
+	if g.viewOffset == 0 {
+		return g.grid[y]
+	}
+
+	i := g.scrollback.count - g.viewOffset + y
+	if i < g.scrollback.count {
+		return g.scrollback.line(i)
+	}
+	return g.grid[i-g.scrollback.count]
+}
+
+// viewCell returns the cell that is shown at the given position of the view. Lines from the
+// scrollback can be shorter than the screen, so the cell might not exist.
+func (g *Window) viewCell(x, y int) (GridCell, bool) {
+	line := g.viewLine(y)
+	if x >= len(line) {
+		return GridCell{}, false
+	}
+	return line[x], true
+}
+
+// viewBackground returns the background pixels of the view. If the view shows the live screen
+// these are the cached background pixels, otherwise they are rendered from the shown lines.
+func (g *Window) viewBackground() *image.RGBA {
+	if g.viewOffset == 0 {
+		return g.bgColors
+	}
+
+	if g.viewBg == nil || g.viewBg.Bounds() != g.bgColors.Bounds() {
+		g.viewBg = image.NewRGBA(g.bgColors.Bounds())
+	}
+
+	for y := 0; y < g.cellsHeight; y++ {
+		for x := 0; x < g.cellsWidth; x++ {
+			cell, ok := g.viewCell(x, y)
+			if !ok {
+				fillCellPixels(g.viewBg, x, y, g.cellWidth, g.cellHeight, g.defaultBg)
+				continue
+			}
+
+			_, bg := g.cellColors(cell)
+			fillCellPixels(g.viewBg, x, y, g.cellWidth, g.cellHeight, bg)
+		}
+	}
+
+	return g.viewBg
+}
+
+// drawScrollIndicator shows the position in the scrollback in the top right corner if the view is scrolled back.
+func (g *Window) drawScrollIndicator(bufferImage *ebiten.Image) {
+	if g.viewOffset == 0 {
+		return
+	}
+
+	label := fmt.Sprintf("[%d/%d]", g.viewOffset, g.scrollback.count)
+	width := len(label) * g.cellWidth
+	x := g.cellsWidth*g.cellWidth - width
+
+	vector.DrawFilledRect(bufferImage, float32(x), 0, float32(width), float32(g.cellHeight), g.selectionColor, false)
+	text.Draw(bufferImage, label, g.fonts.Normal, x, g.cellOffsetY, g.defaultFg)
+}
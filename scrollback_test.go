@@ -0,0 +1,144 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// scrollbackLines returns the characters of the scrollback line by line with trailing spaces removed.
+func scrollbackLines(win *Window) []string {
+	lines := make([]string, win.scrollback.count)
+	for i := range lines {
+		var sb strings.Builder
+		for _, cell := range win.scrollback.line(i) {
+			sb.WriteString(win.cellText(cell))
+		}
+		lines[i] = strings.TrimRight(sb.String(), " ")
+	}
+	return lines
+}
+
+func TestScrollbackRing(t *testing.T) {
+	s := newScrollback(3)
+	for i := 0; i < 5; i++ {
+		s.push([]GridCell{{Char: rune('a' + i)}})
+	}
+
+	assert.Equal(t, 3, s.count)
+	assert.Equal(t, 'c', s.line(0)[0].Char)
+	assert.Equal(t, 'e', s.line(2)[0].Char)
+
+	s.resize(2)
+	assert.Equal(t, 2, s.count)
+	assert.Equal(t, 'd', s.line(0)[0].Char)
+
+	s.clear()
+	assert.Equal(t, 0, s.count)
+
+	disabled := newScrollback(0)
+	disabled.push([]GridCell{{Char: 'a'}})
+	assert.Equal(t, 0, disabled.count)
+}
+
+func TestScrollbackCapture(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+
+	assert.Equal(t, []string{"0", "1"}, scrollbackLines(win))
+	assert.Equal(t, []string{"2", "3", "4"}, screenLines(win))
+
+	// SU on the full screen keeps the lines.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.ScrollUpSeq, 1)))
+	assert.Equal(t, []string{"0", "1", "2"}, scrollbackLines(win))
+
+	// DL and scrolling inside a region don't.
+	win.feed([]byte(fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 1) + fmt.Sprintf(termenv.CSI+termenv.DeleteLineSeq, 1)))
+	win.feed([]byte(termenv.CSI + "2;3r" + fmt.Sprintf(termenv.CSI+termenv.ScrollUpSeq, 1)))
+	assert.Equal(t, 3, win.GetScrollbackLen())
+
+	// The alternate screen has no scrollback.
+	win.feed([]byte(termenv.CSI + "r" + termenv.CSI + "?1049h" + "a\nb\nc\nd\n"))
+	assert.Equal(t, 3, win.GetScrollbackLen())
+	win.feed([]byte(termenv.CSI + "?1049l"))
+
+	// ED 3 clears the scrollback.
+	win.feed([]byte(termenv.CSI + "3J"))
+	assert.Equal(t, 0, win.GetScrollbackLen())
+}
+
+func TestScrollView(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	win.feed([]byte("0\n1\n2\n3\n4"))
+
+	win.ScrollView(1)
+	assert.Equal(t, 1, win.GetViewOffset())
+	assert.Equal(t, '1', win.viewLine(0)[0].Char)
+	assert.Equal(t, '3', win.viewLine(2)[0].Char)
+
+	// The offset is clamped to the scrollback.
+	win.ScrollView(10)
+	assert.Equal(t, 2, win.GetViewOffset())
+	assert.Equal(t, '0', win.viewLine(0)[0].Char)
+
+	// New output keeps the view on the same content.
+	win.feed([]byte("\n5"))
+	assert.Equal(t, 3, win.GetViewOffset())
+	assert.Equal(t, '0', win.viewLine(0)[0].Char)
+
+	// The background of the view is rendered from the shown lines.
+	assert.NotSame(t, win.bgColors, win.viewBackground())
+
+	win.ScrollViewToBottom()
+	assert.Equal(t, 0, win.GetViewOffset())
+	assert.Same(t, win.bgColors, win.viewBackground())
+
+	// Shrinking the scrollback clamps the view.
+	win.ScrollView(3)
+	win.SetScrollbackSize(1)
+	assert.Equal(t, 1, win.GetViewOffset())
+	assert.Equal(t, []string{"2"}, scrollbackLines(win))
+}
+
+// keyAdapter records the key handling calls of the window.
+type keyAdapter struct {
+	EmptyAdapter
+	presses int
+}
+
+func (a *keyAdapter) HandleKeyPress() {
+	a.presses++
+}
+
+type keyFilterAdapter struct {
+	keyAdapter
+	used func(key ebiten.Key) bool
+}
+
+func (a *keyFilterAdapter) HandleKeyPressExcept(used func(key ebiten.Key) bool) {
+	a.used = used
+}
+
+func TestScrollKeysOnlySkipThemselves(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	used := func(key ebiten.Key) bool {
+		return key == ebiten.KeyPageUp
+	}
+
+	// Without pressed scroll keys all input is passed on.
+	adapter := &keyAdapter{}
+	win.inputAdapter = adapter
+	win.handleKeyPress(used)
+	assert.Equal(t, 1, adapter.presses)
+
+	// Adapters that can filter get the other keys of the frame.
+	filter := &keyFilterAdapter{}
+	win.inputAdapter = filter
+	win.handleKeyPress(used)
+	assert.Zero(t, filter.presses)
+	assert.True(t, filter.used(ebiten.KeyPageUp))
+	assert.False(t, filter.used(ebiten.KeyA))
+}
@@ -1,47 +1,46 @@
 package crt
 
 import (
-	"fmt"
 	"github.com/muesli/termenv"
+	"strconv"
 	"strings"
-	"sync"
 )
 
-var sgrMtx = &sync.Mutex{}
-var sgrCache = map[string][]any{}
+type SGRReset struct{}
 
-// extractSGR extracts an SGR ansi sequence from the beginning of the string.
-func extractSGR(s string) (string, bool) {
-	if len(s) < 2 {
-		return "", false
-	}
+type SGRBold struct{}
 
-	if !strings.HasPrefix(s, termenv.CSI) {
-		return "", false
-	}
+type SGRUnsetBold struct{}
 
-	for i := 2; i < len(s); i++ {
-		if s[i] == ' ' || s[i] == termenv.CSI[0] {
-			return "", false
-		}
+type SGRItalic struct{}
 
-		if s[i] == 'm' {
-			return s[:i+1], true
-		}
-	}
+type SGRUnsetItalic struct{}
 
-	return "", false
-}
+type SGRDim struct{}
 
-type SGRReset struct{}
+type SGRUnderline struct{}
 
-type SGRBold struct{}
+type SGRUnsetUnderline struct{}
 
-type SGRUnsetBold struct{}
+type SGRBlink struct{}
 
-type SGRItalic struct{}
+type SGRUnsetBlink struct{}
 
-type SGRUnsetItalic struct{}
+type SGRReverse struct{}
+
+type SGRUnsetReverse struct{}
+
+type SGRConceal struct{}
+
+type SGRUnsetConceal struct{}
+
+type SGRStrikethrough struct{}
+
+type SGRUnsetStrikethrough struct{}
+
+type SGROverline struct{}
+
+type SGRUnsetOverline struct{}
 
 type SGRFgTrueColor struct {
 	R, G, B byte
@@ -59,101 +58,177 @@ type SGRBgColor struct {
 	Id int
 }
 
-// parseSGR parses a single SGR ansi sequence and returns a struct representing the sequence.
+type SGRDefaultFg struct{}
+
+type SGRDefaultBg struct{}
+
+// parseSGR parses a single SGR ansi sequence string (e.g. "\x1b[1m") and returns structs representing the sequence.
 func parseSGR(s string) ([]any, bool) {
-	if !strings.HasPrefix(s, termenv.CSI) {
+	if !strings.HasPrefix(s, termenv.CSI) || !strings.HasSuffix(s, "m") || len(s) <= len(termenv.CSI) {
 		return nil, false
 	}
 
-	s = s[len(termenv.CSI):]
+	return parseSGRParams(s[len(termenv.CSI):len(s)-1], nil)
+}
+
+// parseSGRParams parses the parameters of an SGR sequence (e.g. "1;38;5;2") and appends structs
+// representing the sequence to res, so the slice can be reused.
+func parseSGRParams(s string, res []any) ([]any, bool) {
 	if len(s) == 0 {
-		return nil, false
+		return append(res, SGRReset{}), true
 	}
 
-	sgrMtx.Lock()
-	if cached, ok := sgrCache[s]; ok {
-		sgrMtx.Unlock()
-		return cached, true
+	n := len(res)
+	var buf [16]string
+	codes := splitParams(s, ';', buf[:0])
+	for i := 0; i < len(codes); i++ {
+		code := codes[i]
+
+		// Colon separated sub parameters (e.g. 4:3 or 38:2::255:0:0).
+		var sub []string
+		var subBuf [8]string
+		if strings.IndexByte(code, ':') >= 0 {
+			sub = splitParams(code, ':', subBuf[:0])
+			code, sub = sub[0], sub[1:]
+		}
+
+		switch code {
+		case "", "0":
+			res = append(res, SGRReset{})
+		case "1":
+			res = append(res, SGRBold{})
+		case "2":
+			res = append(res, SGRDim{})
+		case "3":
+			res = append(res, SGRItalic{})
+		case "4":
+			// 4:0 disables the underline, all other styles are drawn as a single underline.
+			if len(sub) > 0 && sub[0] == "0" {
+				res = append(res, SGRUnsetUnderline{})
+			} else {
+				res = append(res, SGRUnderline{})
+			}
+		case "5", "6":
+			res = append(res, SGRBlink{})
+		case "7":
+			res = append(res, SGRReverse{})
+		case "8":
+			res = append(res, SGRConceal{})
+		case "9":
+			res = append(res, SGRStrikethrough{})
+		case "21":
+			res = append(res, SGRUnderline{})
+		case "22":
+			res = append(res, SGRUnsetBold{})
+		case "23":
+			res = append(res, SGRUnsetItalic{})
+		case "24":
+			res = append(res, SGRUnsetUnderline{})
+		case "25":
+			res = append(res, SGRUnsetBlink{})
+		case "27":
+			res = append(res, SGRUnsetReverse{})
+		case "28":
+			res = append(res, SGRUnsetConceal{})
+		case "29":
+			res = append(res, SGRUnsetStrikethrough{})
+		case "30", "31", "32", "33", "34", "35", "36", "37":
+			res = append(res, SGRFgColor{Id: int(code[1] - '0')})
+		case "39":
+			res = append(res, SGRDefaultFg{})
+		case "40", "41", "42", "43", "44", "45", "46", "47":
+			res = append(res, SGRBgColor{Id: int(code[1] - '0')})
+		case "49":
+			res = append(res, SGRDefaultBg{})
+		case "90", "91", "92", "93", "94", "95", "96", "97":
+			res = append(res, SGRFgColor{Id: int(code[1]-'0') + 8})
+		case "100", "101", "102", "103", "104", "105", "106", "107":
+			res = append(res, SGRBgColor{Id: int(code[2]-'0') + 8})
+		case "53":
+			res = append(res, SGROverline{})
+		case "55":
+			res = append(res, SGRUnsetOverline{})
+		case "38", "48":
+			args := sub
+			if sub == nil {
+				args = codes[i+1:]
+			}
+
+			col, n, ok := parseSGRColor(args, code == "48", sub != nil)
+			if !ok {
+				// The remaining parameters can't be interpreted reliably.
+				i = len(codes)
+				continue
+			}
+
+			res = append(res, col)
+			if sub == nil {
+				i += n
+			}
+		}
 	}
-	sgrMtx.Unlock()
 
-	full := s
+	return res, len(res) > n
+}
 
-	if !strings.HasSuffix(s, "m") {
-		return nil, false
+// splitParams splits the parameters at sep and appends them to buf, so short lists don't allocate.
+func splitParams(s string, sep byte, buf []string) []string {
+	for {
+		i := strings.IndexByte(s, sep)
+		if i < 0 {
+			return append(buf, s)
+		}
+		buf = append(buf, s[:i])
+		s = s[i+1:]
 	}
+}
 
-	s = s[:len(s)-1]
-	if len(s) == 0 {
-		return nil, false
+// parseSGRColor parses the arguments of an extended color (38 or 48) like "5;n" or "2;r;g;b".
+// It returns the color and the number of arguments that were used. In the colon form the
+// true color arguments can contain a color space id (e.g. 38:2::r:g:b).
+func parseSGRColor(args []string, bg bool, colon bool) (any, int, bool) {
+	if len(args) == 0 {
+		return nil, 0, false
 	}
 
-	var skips int
-	var res []any
-	for len(s) > 0 {
-		code := strings.SplitN(s, ";", 2)[0]
-
-		if skips > 0 {
-			skips--
-		} else {
-			switch code {
-			case "0":
-				res = append(res, SGRReset{})
-			case "1":
-				res = append(res, SGRBold{})
-			case "22":
-				res = append(res, SGRUnsetBold{})
-			case "3":
-				res = append(res, SGRItalic{})
-			case "23":
-				res = append(res, SGRUnsetItalic{})
-			default:
-				if strings.HasPrefix(s, "38;2;") {
-					var r, g, b byte
-					_, err := fmt.Sscanf(s, "38;2;%d;%d;%d", &r, &g, &b)
-					if err == nil {
-						skips = 4
-						res = append(res, SGRFgTrueColor{r, g, b})
-						continue
-					}
-				} else if strings.HasPrefix(s, "48;2;") {
-					var r, g, b byte
-					_, err := fmt.Sscanf(s, "48;2;%d;%d;%d", &r, &g, &b)
-					if err == nil {
-						skips = 4
-						res = append(res, SGRBgTrueColor{r, g, b})
-						continue
-					}
-				} else if strings.HasPrefix(s, "38;5;") {
-					var id int
-					_, err := fmt.Sscanf(s, "38;5;%d", &id)
-					if err == nil {
-						skips = 2
-						res = append(res, SGRFgColor{id})
-						continue
-					}
-				} else if strings.HasPrefix(s, "48;5;") {
-					var id int
-					_, err := fmt.Sscanf(s, "48;5;%d", &id)
-					if err == nil {
-						skips = 2
-						res = append(res, SGRBgColor{id})
-						continue
-					}
-				}
-			}
+	switch args[0] {
+	case "5":
+		if len(args) < 2 {
+			return nil, 0, false
 		}
 
-		if len(code) >= len(s) {
-			break
+		id, err := strconv.Atoi(args[1])
+		if err != nil || id < 0 || id > 255 {
+			return nil, 0, false
 		}
 
-		s = s[len(code)+1:]
-	}
+		if bg {
+			return SGRBgColor{Id: id}, 2, true
+		}
+		return SGRFgColor{Id: id}, 2, true
+	case "2":
+		rgb := args[1:]
+		if colon && len(rgb) >= 4 {
+			rgb = rgb[1:]
+		}
+		if len(rgb) < 3 {
+			return nil, 0, false
+		}
+
+		var vals [3]byte
+		for i := range vals {
+			val, err := strconv.Atoi(rgb[i])
+			if err != nil || val < 0 || val > 255 {
+				return nil, 0, false
+			}
+			vals[i] = byte(val)
+		}
 
-	sgrMtx.Lock()
-	sgrCache[full] = res
-	sgrMtx.Unlock()
+		if bg {
+			return SGRBgTrueColor{vals[0], vals[1], vals[2]}, 4, true
+		}
+		return SGRFgTrueColor{vals[0], vals[1], vals[2]}, 4, true
+	}
 
-	return res, len(res) > 0
+	return nil, 0, false
 }
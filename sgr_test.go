@@ -14,16 +14,13 @@ func TestSGR(t *testing.T) {
 	testString := lip.NewStyle().Bold(true).Foreground(lipgloss.Color("#ff00ff")).Render("Hello World") + "asdasdasdasdasd" + lip.NewStyle().Italic(true).Background(lipgloss.Color("#ff00ff")).Render("Hello World")
 
 	var sequences []any
-	for i := 0; i < len(testString); i++ {
-		sgr, ok := extractSGR(testString[i:])
-		if ok {
-			i += len(sgr) - 1
-
-			if res, ok := parseSGR(sgr); ok {
+	NewParser().Feed([]byte(testString), func(event any) {
+		if sgr, ok := event.(CSIEvent); ok && sgr.IsSGR() {
+			if res, ok := parseSGR(sgr.String()); ok {
 				sequences = append(sequences, res...)
 			}
 		}
-	}
+	})
 
 	assert.Equal(t, []any{
 		SGRBold{},
@@ -34,3 +31,63 @@ func TestSGR(t *testing.T) {
 		SGRReset{},
 	}, sequences)
 }
+
+func TestSGRAttributes(t *testing.T) {
+	buf := &bytes.Buffer{}
+	lip := lipgloss.NewRenderer(buf, termenv.WithProfile(termenv.TrueColor))
+
+	tests := []struct {
+		name  string
+		style lipgloss.Style
+		want  any
+	}{
+		{"faint", lip.NewStyle().Faint(true), SGRDim{}},
+		{"underline", lip.NewStyle().Underline(true), SGRUnderline{}},
+		{"blink", lip.NewStyle().Blink(true), SGRBlink{}},
+		{"reverse", lip.NewStyle().Reverse(true), SGRReverse{}},
+		{"strikethrough", lip.NewStyle().Strikethrough(true), SGRStrikethrough{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var sequences []any
+			NewParser().Feed([]byte(test.style.Render("x")), func(event any) {
+				if sgr, ok := event.(CSIEvent); ok && sgr.IsSGR() {
+					if res, ok := parseSGR(sgr.String()); ok {
+						sequences = append(sequences, res...)
+					}
+				}
+			})
+
+			assert.Contains(t, sequences, test.want)
+			assert.Equal(t, SGRReset{}, sequences[len(sequences)-1])
+		})
+	}
+}
+
+func TestSGRCodes(t *testing.T) {
+	tests := []struct {
+		seq  string
+		want []any
+	}{
+		{"m", []any{SGRReset{}}},
+		{"2;4;5;7;8;9;53m", []any{SGRDim{}, SGRUnderline{}, SGRBlink{}, SGRReverse{}, SGRConceal{}, SGRStrikethrough{}, SGROverline{}}},
+		{"22;24;25;27;28;29;55m", []any{SGRUnsetBold{}, SGRUnsetUnderline{}, SGRUnsetBlink{}, SGRUnsetReverse{}, SGRUnsetConceal{}, SGRUnsetStrikethrough{}, SGRUnsetOverline{}}},
+		{"4:3;4:0m", []any{SGRUnderline{}, SGRUnsetUnderline{}}},
+		{"38;5;1;1m", []any{SGRFgColor{Id: 1}, SGRBold{}}},
+		{"48;2;10;20;0;3m", []any{SGRBgTrueColor{R: 10, G: 20, B: 0}, SGRItalic{}}},
+		{"38:2::1:2:3;48:5:7m", []any{SGRFgTrueColor{R: 1, G: 2, B: 3}, SGRBgColor{Id: 7}}},
+		{"38:2:1:2:3m", []any{SGRFgTrueColor{R: 1, G: 2, B: 3}}},
+		{"1;38;5m", []any{SGRBold{}}},
+		{"30;37;39m", []any{SGRFgColor{Id: 0}, SGRFgColor{Id: 7}, SGRDefaultFg{}}},
+		{"40;47;49m", []any{SGRBgColor{Id: 0}, SGRBgColor{Id: 7}, SGRDefaultBg{}}},
+		{"90;97m", []any{SGRFgColor{Id: 8}, SGRFgColor{Id: 15}}},
+		{"100;107m", []any{SGRBgColor{Id: 8}, SGRBgColor{Id: 15}}},
+	}
+
+	for _, test := range tests {
+		res, ok := parseSGR(termenv.CSI + test.seq)
+		assert.True(t, ok, test.seq)
+		assert.Equal(t, test.want, res, test.seq)
+	}
+}
@@ -0,0 +1,135 @@
+package crt
+
+import "image/color"
+
+// Theme is a set of colors for the terminal. Colors that are nil are left unchanged when the theme is applied.
+type Theme struct {
+	// Palette holds the 16 base colors.
+	Palette Palette
+
+	// Foreground is the default text color.
+	Foreground color.Color
+
+	// Background is the default background color.
+	Background color.Color
+
+	// Cursor is the color of the cursor.
+	Cursor color.Color
+
+	// Selection is the background color of selected text.
+	Selection color.Color
+}
+
+// DefaultTheme is the theme that is used if no other theme is set.
+var DefaultTheme = Theme{
+	Palette:    DefaultPalette,
+	Foreground: color.White,
+	Background: color.Black,
+	Cursor:     color.RGBA{R: 255, G: 255, B: 255, A: 100},
+	Selection:  color.RGBA{R: 0x44, G: 0x44, B: 0x44, A: 0xff},
+}
+
+// AmberTheme imitates the amber phosphor of old monochrome monitors.
+var AmberTheme = monochromeTheme(color.RGBA{R: 0xff, G: 0xb0, B: 0x00, A: 0xff}, color.RGBA{R: 0x1a, G: 0x10, B: 0x00, A: 0xff})
+
+// GreenPhosphorTheme imitates the green phosphor of old monochrome monitors.
+var GreenPhosphorTheme = monochromeTheme(color.RGBA{R: 0x33, G: 0xff, B: 0x33, A: 0xff}, color.RGBA{R: 0x00, G: 0x14, B: 0x00, A: 0xff})
+
+// SolarizedDarkTheme is the dark variant of the Solarized color scheme by Ethan Schoonover.
+var SolarizedDarkTheme = Theme{
+	Palette: Palette{
+		hexColor(0x073642), hexColor(0xdc322f), hexColor(0x859900), hexColor(0xb58900),
+		hexColor(0x268bd2), hexColor(0xd33682), hexColor(0x2aa198), hexColor(0xeee8d5),
+		hexColor(0x002b36), hexColor(0xcb4b16), hexColor(0x586e75), hexColor(0x657b83),
+		hexColor(0x839496), hexColor(0x6c71c4), hexColor(0x93a1a1), hexColor(0xfdf6e3),
+	},
+	Foreground: hexColor(0x839496),
+	Background: hexColor(0x002b36),
+	Cursor:     hexColor(0x93a1a1),
+	Selection:  hexColor(0x073642),
+}
+
+// monochromeTheme creates a theme that shades all palette colors between the background and the foreground.
+func monochromeTheme(fg, bg color.RGBA) Theme {
+	var palette Palette
+	for i := range palette {
+		// Normal colors use the lower, bright colors the upper part of the range.
+		level := float64(i%8+1) / 8 * 0.7
+		if i >= 8 {
+			level += 0.3
+		}
+		if i == 0 {
+			level = 0
+		}
+
+		palette[i] = color.RGBA{
+			R: uint8(float64(bg.R) + (float64(fg.R)-float64(bg.R))*level),
+			G: uint8(float64(bg.G) + (float64(fg.G)-float64(bg.G))*level),
+			B: uint8(float64(bg.B) + (float64(fg.B)-float64(bg.B))*level),
+			A: 0xff,
+		}
+	}
+
+	return Theme{
+		Palette:    palette,
+		Foreground: fg,
+		Background: bg,
+		Cursor:     color.RGBA{R: fg.R, G: fg.G, B: fg.B, A: 100},
+		Selection:  palette[8],
+	}
+}
+
+// hexColor converts a 0xRRGGBB value to a color.
+func hexColor(rgb uint32) color.RGBA {
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}
+}
+
+// WithTheme sets the theme of the window.
+func WithTheme(theme Theme) WindowOption {
+	return func(window *Window) {
+		window.SetTheme(theme)
+	}
+}
+
+// GetTheme returns the colors that are currently used by the window.
+func (g *Window) GetTheme() Theme {
+	return Theme{
+		Palette:    g.palette,
+		Foreground: g.defaultFg,
+		Background: g.defaultBg,
+		Cursor:     g.cursorColor,
+		Selection:  g.selectionColor,
+	}
+}
+
+// SetTheme changes the colors of the window. Existing cells that use a palette
+// or default color are drawn with the matching color of the new theme.
+func (g *Window) SetTheme(theme Theme) {
+	for i := range theme.Palette {
+		if theme.Palette[i] != nil {
+			g.palette[i] = theme.Palette[i]
+		}
+	}
+	if theme.Foreground != nil {
+		g.defaultFg = theme.Foreground
+	}
+	if theme.Background != nil {
+		g.defaultBg = theme.Background
+	}
+	if theme.Cursor != nil {
+		g.cursorColor = theme.Cursor
+	}
+	if theme.Selection != nil {
+		g.selectionColor = theme.Selection
+	}
+
+	// The colors of the theme replace the colors that were changed by the application.
+	for index := range g.savedColors {
+		if index < len(g.palette) || index >= dynamicFg {
+			delete(g.savedColors, index)
+		}
+	}
+
+	g.RecalculateBackgrounds()
+	g.InvalidateBuffer()
+}
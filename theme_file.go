@@ -0,0 +1,310 @@
+package crt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LoadThemeFile loads a theme from a file. The format is detected by the file extension:
+//
+// - .itermcolors: iTerm2 color preset
+// - .toml: Alacritty color configuration
+// - .json: Windows Terminal color scheme
+//
+// Colors that are missing in the file are nil, so they are left unchanged when the theme is applied.
+func LoadThemeFile(file string) (Theme, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".itermcolors":
+		return ParseITermTheme(data)
+	case ".toml":
+		return ParseAlacrittyTheme(data)
+	case ".json":
+		return ParseWindowsTerminalTheme(data)
+	}
+
+	return Theme{}, fmt.Errorf("unsupported theme format: %s", file)
+}
+
+// ParseITermTheme parses an iTerm2 color preset (.itermcolors).
+func ParseITermTheme(data []byte) (Theme, error) {
+	var plist struct {
+		Dict struct {
+			Entries []plistEntry `xml:",any"`
+		} `xml:"dict"`
+	}
+	if err := xml.Unmarshal(data, &plist); err != nil {
+		return Theme{}, err
+	}
+
+	var theme Theme
+	entries := plist.Dict.Entries
+	for i := 0; i+1 < len(entries); i += 2 {
+		if entries[i].XMLName.Local != "key" || entries[i+1].XMLName.Local != "dict" {
+			return Theme{}, errors.New("invalid iTerm2 color preset")
+		}
+
+		col, err := entries[i+1].color()
+		if err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", entries[i].Value, err)
+		}
+
+		var index int
+		switch key := entries[i].Value; key {
+		case "Foreground Color":
+			theme.Foreground = col
+		case "Background Color":
+			theme.Background = col
+		case "Cursor Color":
+			theme.Cursor = col
+		case "Selection Color":
+			theme.Selection = col
+		default:
+			if _, err := fmt.Sscanf(key, "Ansi %d Color", &index); err == nil && index >= 0 && index < len(theme.Palette) {
+				theme.Palette[index] = col
+			}
+		}
+	}
+
+	return theme, nil
+}
+
+// plistEntry is a single element of a plist dictionary.
+type plistEntry struct {
+	XMLName xml.Name
+	Value   string       `xml:",chardata"`
+	Entries []plistEntry `xml:",any"`
+}
+
+// color converts a plist color dictionary with float components to a color.
+func (e plistEntry) color() (color.Color, error) {
+	col := color.RGBA{A: 0xff}
+	for i := 0; i+1 < len(e.Entries); i += 2 {
+		var component *uint8
+		switch e.Entries[i].Value {
+		case "Red Component":
+			component = &col.R
+		case "Green Component":
+			component = &col.G
+		case "Blue Component":
+			component = &col.B
+		default:
+			continue
+		}
+
+		val, err := strconv.ParseFloat(strings.TrimSpace(e.Entries[i+1].Value), 64)
+		if err != nil {
+			return nil, err
+		}
+		*component = uint8(val*255 + 0.5)
+	}
+	return col, nil
+}
+
+// alacrittyColorNames are the names of the palette colors in the order of the palette.
+var alacrittyColorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// ParseAlacrittyTheme parses the colors section of an Alacritty TOML configuration.
+// Only the subset of TOML that is used by color configurations is supported.
+func ParseAlacrittyTheme(data []byte) (Theme, error) {
+	var theme Theme
+
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") && strings.HasSuffix(text, "]") {
+			section = strings.TrimSpace(strings.Trim(text, "[]"))
+			continue
+		}
+
+		key, val, ok := strings.Cut(text, "=")
+		if !ok {
+			return Theme{}, fmt.Errorf("line %d: expected key = value", line)
+		}
+
+		key = strings.TrimSpace(key)
+		val, quoted, err := parseTOMLString(strings.TrimSpace(val))
+		if err != nil {
+			return Theme{}, fmt.Errorf("line %d: %s: %w", line, key, err)
+		}
+		if !quoted {
+			continue
+		}
+
+		col, err := parseHexColor(val)
+		if err != nil {
+			// Values like "CellForeground" are not colors.
+			continue
+		}
+
+		switch section {
+		case "colors.primary":
+			switch key {
+			case "foreground":
+				theme.Foreground = col
+			case "background":
+				theme.Background = col
+			}
+		case "colors.cursor":
+			if key == "cursor" {
+				theme.Cursor = col
+			}
+		case "colors.selection":
+			if key == "background" {
+				theme.Selection = col
+			}
+		case "colors.normal", "colors.bright":
+			for i := range alacrittyColorNames {
+				if alacrittyColorNames[i] != key {
+					continue
+				}
+
+				if section == "colors.bright" {
+					i += 8
+				}
+				theme.Palette[i] = col
+			}
+		}
+	}
+
+	return theme, scanner.Err()
+}
+
+// windowsTerminalScheme is a color scheme of the Windows Terminal settings.
+type windowsTerminalScheme struct {
+	Black               string `json:"black"`
+	Red                 string `json:"red"`
+	Green               string `json:"green"`
+	Yellow              string `json:"yellow"`
+	Blue                string `json:"blue"`
+	Purple              string `json:"purple"`
+	Cyan                string `json:"cyan"`
+	White               string `json:"white"`
+	BrightBlack         string `json:"brightBlack"`
+	BrightRed           string `json:"brightRed"`
+	BrightGreen         string `json:"brightGreen"`
+	BrightYellow        string `json:"brightYellow"`
+	BrightBlue          string `json:"brightBlue"`
+	BrightPurple        string `json:"brightPurple"`
+	BrightCyan          string `json:"brightCyan"`
+	BrightWhite         string `json:"brightWhite"`
+	Foreground          string `json:"foreground"`
+	Background          string `json:"background"`
+	CursorColor         string `json:"cursorColor"`
+	SelectionBackground string `json:"selectionBackground"`
+}
+
+// ParseWindowsTerminalTheme parses a Windows Terminal color scheme. If the data is a
+// settings file with a list of schemes the first scheme is used.
+func ParseWindowsTerminalTheme(data []byte) (Theme, error) {
+	var settings struct {
+		Schemes []windowsTerminalScheme `json:"schemes"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return Theme{}, err
+	}
+
+	var scheme windowsTerminalScheme
+	if len(settings.Schemes) > 0 {
+		scheme = settings.Schemes[0]
+	} else if err := json.Unmarshal(data, &scheme); err != nil {
+		return Theme{}, err
+	}
+
+	var theme Theme
+	colors := []struct {
+		val    string
+		target *color.Color
+	}{
+		{scheme.Black, &theme.Palette[0]},
+		{scheme.Red, &theme.Palette[1]},
+		{scheme.Green, &theme.Palette[2]},
+		{scheme.Yellow, &theme.Palette[3]},
+		{scheme.Blue, &theme.Palette[4]},
+		{scheme.Purple, &theme.Palette[5]},
+		{scheme.Cyan, &theme.Palette[6]},
+		{scheme.White, &theme.Palette[7]},
+		{scheme.BrightBlack, &theme.Palette[8]},
+		{scheme.BrightRed, &theme.Palette[9]},
+		{scheme.BrightGreen, &theme.Palette[10]},
+		{scheme.BrightYellow, &theme.Palette[11]},
+		{scheme.BrightBlue, &theme.Palette[12]},
+		{scheme.BrightPurple, &theme.Palette[13]},
+		{scheme.BrightCyan, &theme.Palette[14]},
+		{scheme.BrightWhite, &theme.Palette[15]},
+		{scheme.Foreground, &theme.Foreground},
+		{scheme.Background, &theme.Background},
+		{scheme.CursorColor, &theme.Cursor},
+		{scheme.SelectionBackground, &theme.Selection},
+	}
+
+	for i := range colors {
+		if colors[i].val == "" {
+			continue
+		}
+
+		col, err := parseHexColor(colors[i].val)
+		if err != nil {
+			return Theme{}, err
+		}
+		*colors[i].target = col
+	}
+
+	return theme, nil
+}
+
+// parseTOMLString returns the content of a quoted TOML value. A trailing comment is
+// ignored. Values that are not quoted (e.g. numbers) are returned with quoted set to false.
+func parseTOMLString(val string) (string, bool, error) {
+	if val == "" || (val[0] != '"' && val[0] != '\'') {
+		return val, false, nil
+	}
+
+	end := strings.IndexByte(val[1:], val[0])
+	if end < 0 {
+		return "", false, fmt.Errorf("unterminated string %s", val)
+	}
+
+	rest := strings.TrimSpace(val[end+2:])
+	if rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", false, fmt.Errorf("unexpected %q after string", rest)
+	}
+
+	return val[1 : end+1], true, nil
+}
+
+// parseHexColor parses colors in the form #rrggbb, 0xrrggbb or #rgb.
+func parseHexColor(s string) (color.Color, error) {
+	hex := strings.TrimPrefix(strings.TrimPrefix(s, "#"), "0x")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return nil, fmt.Errorf("invalid color: %s", s)
+	}
+
+	rgb, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color: %s", s)
+	}
+
+	return hexColor(uint32(rgb)), nil
+}
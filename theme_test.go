@@ -0,0 +1,149 @@
+package crt
+
+import (
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testITermTheme = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Ansi 1 Color</key>
+	<dict>
+		<key>Blue Component</key>
+		<real>0.0</real>
+		<key>Green Component</key>
+		<real>0.0</real>
+		<key>Red Component</key>
+		<real>1</real>
+	</dict>
+	<key>Background Color</key>
+	<dict>
+		<key>Alpha Component</key>
+		<real>1</real>
+		<key>Blue Component</key>
+		<real>0.2</real>
+		<key>Color Space</key>
+		<string>sRGB</string>
+		<key>Green Component</key>
+		<real>0.2</real>
+		<key>Red Component</key>
+		<real>0.2</real>
+	</dict>
+</dict>
+</plist>`
+
+const testAlacrittyTheme = `# Colors
+[colors.primary]
+background = '#1d1f21'
+foreground = "0xc5c8c6" # text
+
+[colors.cursor]
+text = 'CellBackground'
+cursor = '#ffffff'
+
+[colors.normal]
+red = '#cc6666'
+
+[colors.bright]
+red = '#ff3333'
+`
+
+const testWindowsTerminalTheme = `{
+	"name": "Test",
+	"red": "#C50F1F",
+	"brightRed": "#E74856",
+	"foreground": "#CCCCCC",
+	"background": "#0C0C0C",
+	"cursorColor": "#FFFFFF",
+	"selectionBackground": "#FFFFFF"
+}`
+
+func TestParseThemes(t *testing.T) {
+	iterm, err := ParseITermTheme([]byte(testITermTheme))
+	assert.NoError(t, err)
+	assert.Equal(t, color.Color(hexColor(0xff0000)), iterm.Palette[1])
+	assert.Equal(t, color.Color(hexColor(0x333333)), iterm.Background)
+	assert.Nil(t, iterm.Palette[0])
+	assert.Nil(t, iterm.Foreground)
+
+	alacritty, err := ParseAlacrittyTheme([]byte(testAlacrittyTheme))
+	assert.NoError(t, err)
+	assert.Equal(t, color.Color(hexColor(0x1d1f21)), alacritty.Background)
+	assert.Equal(t, color.Color(hexColor(0xc5c8c6)), alacritty.Foreground)
+	assert.Equal(t, color.Color(hexColor(0xffffff)), alacritty.Cursor)
+	assert.Equal(t, color.Color(hexColor(0xcc6666)), alacritty.Palette[1])
+	assert.Equal(t, color.Color(hexColor(0xff3333)), alacritty.Palette[9])
+
+	_, err = ParseAlacrittyTheme([]byte("[colors.primary]\nforeground = '#c0c0c0' text\n"))
+	assert.ErrorContains(t, err, "foreground")
+
+	wt, err := ParseWindowsTerminalTheme([]byte(testWindowsTerminalTheme))
+	assert.NoError(t, err)
+	assert.Equal(t, color.Color(hexColor(0xc50f1f)), wt.Palette[1])
+	assert.Equal(t, color.Color(hexColor(0xe74856)), wt.Palette[9])
+	assert.Equal(t, color.Color(hexColor(0xcccccc)), wt.Foreground)
+	assert.Equal(t, color.Color(hexColor(0x0c0c0c)), wt.Background)
+	assert.Equal(t, color.Color(hexColor(0xffffff)), wt.Selection)
+
+	wtSettings, err := ParseWindowsTerminalTheme([]byte(`{"schemes": [` + testWindowsTerminalTheme + `]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, wt, wtSettings)
+
+	_, err = ParseWindowsTerminalTheme([]byte(`{"red": "nope"}`))
+	assert.Error(t, err)
+}
+
+func TestLoadThemeFile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"test.itermcolors": testITermTheme,
+		"test.toml":        testAlacrittyTheme,
+		"test.json":        testWindowsTerminalTheme,
+	} {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+
+		theme, err := LoadThemeFile(filepath.Join(dir, name))
+		assert.NoError(t, err, name)
+		assert.NotNil(t, theme.Palette[1], name)
+	}
+
+	_, err := LoadThemeFile(filepath.Join(dir, "test.txt"))
+	assert.Error(t, err)
+}
+
+func TestSetThemeRecolors(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	win.feed([]byte("A" + termenv.CSI + "31mB" + termenv.CSI + "38;2;1;2;3mC" + termenv.CSI + "31m"))
+
+	win.SetTheme(SolarizedDarkTheme)
+
+	fg, bg := win.cellColors(win.grid[0][0])
+	assert.Equal(t, SolarizedDarkTheme.Foreground, fg)
+	assert.Equal(t, SolarizedDarkTheme.Background, bg)
+	fg, _ = win.cellColors(win.grid[0][1])
+	assert.Equal(t, SolarizedDarkTheme.Palette[1], fg)
+	fg, _ = win.cellColors(win.grid[0][2])
+	assert.Equal(t, color.Color(color.RGBA{R: 1, G: 2, B: 3, A: 255}), fg)
+	_, bg = win.cellColors(win.grid[1][5])
+	assert.Equal(t, SolarizedDarkTheme.Background, bg)
+	assert.Equal(t, SolarizedDarkTheme, win.GetTheme())
+
+	// The background pixels use the new background.
+	assert.Equal(t, color.Color(SolarizedDarkTheme.Background), win.bgColors.At(0, 0))
+}
+
+func TestWithThemeKeepsMissingColors(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	WithTheme(Theme{Foreground: hexColor(0xffb000)})(win)
+
+	theme := win.GetTheme()
+	assert.Equal(t, color.Color(hexColor(0xffb000)), theme.Foreground)
+	assert.Equal(t, DefaultTheme.Palette, theme.Palette)
+	assert.Equal(t, DefaultTheme.Cursor, theme.Cursor)
+}
@@ -0,0 +1,41 @@
+package crt
+
+import "github.com/mattn/go-runewidth"
+
+// wideSpacer is the character of the cell that holds the right half of a wide character. It is also
+// used for the last cell of a line that was left empty because a wide character didn't fit anymore.
+const wideSpacer rune = 0
+
+// runeWidth returns the number of cells a character occupies, which is 0, 1 or 2.
+// The width of a grapheme cluster is the width of its first rune.
+func (g *Window) runeWidth(r rune) int {
+	return runewidth.RuneWidth(g.graphemes.baseRune(r))
+}
+
+// isWideLead returns true if the cell at x holds the left half of a wide character.
+func (g *Window) isWideLead(line []GridCell, x int) bool {
+	return x >= 0 && x+1 < len(line) && line[x+1].Char == wideSpacer && g.runeWidth(line[x].Char) == 2
+}
+
+// fixWideCell blanks the cell at x of line y if it is one half of a wide character
+// whose other half was overwritten. The colors of the cell are kept.
+func (g *Window) fixWideCell(y, x int) {
+	if x < 0 || x >= g.cellsWidth {
+		return
+	}
+
+	line := g.grid[y]
+	switch {
+	case line[x].Char == wideSpacer && !g.isWideLead(line, x-1):
+		// A leading spacer at the end of a wrapped line has no left half.
+		if x == g.cellsWidth-1 && line[x].Attributes&attrWrapline != 0 {
+			return
+		}
+	case g.runeWidth(line[x].Char) == 2 && !g.isWideLead(line, x):
+	default:
+		return
+	}
+
+	line[x].Char = ' '
+	g.InvalidateBuffer()
+}
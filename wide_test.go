@@ -0,0 +1,69 @@
+package crt
+
+import (
+	"fmt"
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWideCharacters(t *testing.T) {
+	win := newTestWindow(t, 10, 3)
+	win.feed([]byte("a中b😀"))
+
+	assert.Equal(t, "a中b😀", screenLines(win)[0])
+	assert.Equal(t, 6, win.cursorX)
+	assert.Equal(t, '中', win.grid[0][1].Char)
+	assert.Equal(t, wideSpacer, win.grid[0][2].Char)
+	assert.Equal(t, 'b', win.grid[0][3].Char)
+	assert.Equal(t, wideSpacer, win.grid[0][5].Char)
+}
+
+func TestWideCharacterOverwrite(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		input  string
+		expect string
+	}{
+		{"left half", "中文" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 1) + "x", "x 文"},
+		{"right half", "中文" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 2) + "x", " x文"},
+		{"shifted wide", "中文" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 2) + "字", " 字"},
+		{"erase", "中文" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 2) + termenv.CSI + "1X", "  文"},
+		{"delete", "中文" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 1) + termenv.CSI + "1P", " 文"},
+		{"insert", "中文" + fmt.Sprintf(termenv.CSI+termenv.CursorPositionSeq, 1, 2) + termenv.CSI + "1@", "   文"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			win := newTestWindow(t, 10, 2)
+			win.feed([]byte(test.input))
+			assert.Equal(t, test.expect, screenLines(win)[0])
+
+			// No half of a wide character is left behind.
+			line := win.grid[0]
+			for x := range line {
+				if line[x].Char == wideSpacer {
+					assert.True(t, win.isWideLead(line, x-1), x)
+				}
+			}
+		})
+	}
+}
+
+func TestWideCharacterWrap(t *testing.T) {
+	win := newTestWindow(t, 5, 3)
+	win.feed([]byte("abcd中"))
+
+	assert.Equal(t, []string{"abcd", "中", ""}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+
+	// The empty cell before the wrapped character is not kept on reflow.
+	win.Resize(6, 3)
+	assert.Equal(t, []string{"abcd中", "", ""}, screenLines(win))
+	assert.Equal(t, 6, win.cursorX)
+
+	// Wide characters are not split by reflow.
+	win.Resize(5, 3)
+	assert.Equal(t, []string{"abcd", "中", ""}, screenLines(win))
+	assert.Equal(t, 2, win.cursorX)
+	assert.Equal(t, 1, win.cursorY)
+}
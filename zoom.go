@@ -0,0 +1,115 @@
+package crt
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// ZoomMode selects what is kept when the font size changes.
+type ZoomMode int
+
+const (
+	// ZoomKeepWindowSize keeps the size of the window and changes the number of cells.
+	ZoomKeepWindowSize ZoomMode = iota
+
+	// ZoomKeepCellCount keeps the number of cells and resizes the window.
+	ZoomKeepCellCount
+)
+
+const (
+	// zoomStep is the change of the font size per zoom key press.
+	zoomStep = 1.0
+
+	// minFontSize is the smallest font size that can be selected by zooming.
+	minFontSize = 4.0
+)
+
+// WithZoomMode selects what is kept when the font size changes.
+func WithZoomMode(mode ZoomMode) WindowOption {
+	return func(window *Window) {
+		window.zoomMode = mode
+	}
+}
+
+// WithZoomKeys enables or disables the zoom key bindings Ctrl+Plus, Ctrl+Minus and Ctrl+0. They are enabled by default.
+func WithZoomKeys(enabled bool) WindowOption {
+	return func(window *Window) {
+		window.noZoomKeys = !enabled
+	}
+}
+
+// cellMetrics returns the size of a cell and the offset of the baseline for the normal face.
+func cellMetrics(fonts Fonts) (int, int, int) {
+	bounds, _, _ := fonts.Normal.GlyphBounds([]rune("█")[0])
+	size := bounds.Max.Sub(bounds.Min)
+
+	return size.X.Ceil(), size.Y.Ceil(), -bounds.Min.Y.Ceil()
+}
+
+// GetFontSize returns the current font size or zero if the fonts weren't loaded by LoadFaces or LoadFacesBytes.
+func (g *Window) GetFontSize() float64 {
+	return g.fonts.Size()
+}
+
+// SetFontSize re-creates the fonts from the original font data in the given size. Depending on
+// the zoom mode either the number of cells or the size of the window changes. This only works
+// for fonts that were loaded by LoadFaces or LoadFacesBytes.
+func (g *Window) SetFontSize(size float64) error {
+	fonts, err := g.fonts.WithSize(size)
+	if err != nil {
+		return err
+	}
+
+	g.SetFonts(fonts)
+	return nil
+}
+
+// SetFonts changes the fonts of the window. Depending on the zoom mode either
+// the number of cells or the size of the window changes.
+func (g *Window) SetFonts(fonts Fonts) {
+	g.fonts = fonts
+	g.cellWidth, g.cellHeight, g.cellOffsetY = cellMetrics(fonts)
+
+	switch g.zoomMode {
+	case ZoomKeepWindowSize:
+		// The cells are fit into the whole window, so the pixels left over by the
+		// previous font size are not lost.
+		cellsWidth, cellsHeight := g.windowWidth/g.cellWidth, g.windowHeight/g.cellHeight
+		if cellsWidth != g.cellsWidth || cellsHeight != g.cellsHeight {
+			g.Resize(cellsWidth, cellsHeight)
+			return
+		}
+		g.resizeBuffers()
+	case ZoomKeepCellCount:
+		g.resizeBuffers()
+		g.windowWidth, g.windowHeight = g.cellsWidth*g.cellWidth, g.cellsHeight*g.cellHeight
+		ebiten.SetWindowSize(int(float64(g.windowWidth)/DeviceScale()), int(float64(g.windowHeight)/DeviceScale()))
+	}
+}
+
+// handleZoomKeys changes the font size if a zoom key binding was pressed and returns true if it did.
+func (g *Window) handleZoomKeys() bool {
+	if g.noZoomKeys || g.fonts.source == nil || !ebiten.IsKeyPressed(ebiten.KeyControl) {
+		return false
+	}
+
+	size := g.fonts.Size()
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.KeyEqual), inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd):
+		size += zoomStep
+	case inpututil.IsKeyJustPressed(ebiten.KeyMinus), inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract):
+		size -= zoomStep
+		if size < minFontSize {
+			size = minFontSize
+		}
+	case inpututil.IsKeyJustPressed(ebiten.KeyDigit0), inpututil.IsKeyJustPressed(ebiten.KeyNumpad0):
+		size = g.baseFonts.Size()
+	default:
+		return false
+	}
+
+	if size > 0 && size != g.fonts.Size() {
+		_ = g.SetFontSize(size)
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package crt
+
+import (
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goitalic"
+	"golang.org/x/image/font/gofont/gomono"
+	"testing"
+)
+
+// newFontWindow creates a window that uses fonts which were loaded from font data.
+func newFontWindow(t *testing.T, width, height int, options ...WindowOption) *Window {
+	t.Setenv("CRT_DEVICE_SCALE", "1")
+
+	fonts, err := LoadFacesBytes(gomono.TTF, gobold.TTF, goitalic.TTF, 72, 12)
+	assert.NoError(t, err)
+
+	win, err := NewGame(width, height, fonts, nil, NewEmptyAdapter(), nil)
+	assert.NoError(t, err)
+
+	for _, opt := range options {
+		opt(win)
+	}
+	return win
+}
+
+func TestSetFontSizeKeepWindowSize(t *testing.T) {
+	win := newFontWindow(t, 400, 300)
+	adapter := &sizeAdapter{}
+	win.inputAdapter = adapter
+
+	cellsWidth, cellsHeight := win.GetCellsWidth(), win.GetCellsHeight()
+	win.feed([]byte("hello"))
+
+	assert.NoError(t, win.SetFontSize(24))
+	assert.Equal(t, 24.0, win.GetFontSize())
+	assert.Less(t, win.GetCellsWidth(), cellsWidth)
+	assert.Less(t, win.GetCellsHeight(), cellsHeight)
+	assert.Equal(t, []WindowSize{{Width: win.GetCellsWidth(), Height: win.GetCellsHeight()}}, adapter.sizes)
+	assert.Equal(t, win.GetCellsWidth()*win.cellWidth, win.bgColors.Bounds().Dx())
+	assert.Equal(t, "hello", screenLines(win)[0])
+}
+
+func TestSetFontSizeRoundTrip(t *testing.T) {
+	win := newFontWindow(t, 403, 301)
+	cellsWidth, cellsHeight := win.GetCellsWidth(), win.GetCellsHeight()
+
+	// The pixels that are left over at one size are not lost at the next one.
+	for i := 0; i < 5; i++ {
+		for _, size := range []float64{13, 17, 23, 12} {
+			assert.NoError(t, win.SetFontSize(size))
+		}
+	}
+	assert.Equal(t, cellsWidth, win.GetCellsWidth())
+	assert.Equal(t, cellsHeight, win.GetCellsHeight())
+}
+
+func TestSetFontSizeKeepCellCount(t *testing.T) {
+	win := newFontWindow(t, 400, 300, WithZoomMode(ZoomKeepCellCount))
+	cellsWidth, cellsHeight, cellWidth := win.GetCellsWidth(), win.GetCellsHeight(), win.cellWidth
+
+	assert.NoError(t, win.SetFontSize(24))
+	assert.Equal(t, cellsWidth, win.GetCellsWidth())
+	assert.Equal(t, cellsHeight, win.GetCellsHeight())
+	assert.Greater(t, win.cellWidth, cellWidth)
+	assert.Equal(t, cellsWidth*win.cellWidth, win.bgColors.Bounds().Dx())
+}
+
+func TestSetFontSizeWithoutFontData(t *testing.T) {
+	win := newTestWindow(t, 10, 2)
+	assert.Equal(t, 0.0, win.GetFontSize())
+	assert.Error(t, win.SetFontSize(20))
+
+	_, err := Fonts{Normal: basicfont.Face7x13}.WithSize(20)
+	assert.Error(t, err)
+}